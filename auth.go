@@ -0,0 +1,127 @@
+package schemaregistry
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/tryfix/errors"
+)
+
+const defaultHTTPTimeout = 5 * time.Second
+
+// TokenSource provides bearer tokens for registry requests. Token is called for every request, so implementations
+// are responsible for caching and refreshing the token
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// TokenSourceFunc is an adapter to allow the use of ordinary functions as a TokenSource
+type TokenSourceFunc func() (string, error)
+
+// Token calls f()
+func (f TokenSourceFunc) Token() (string, error) {
+	return f()
+}
+
+// StaticTokenSource returns a TokenSource which always returns the given token
+func StaticTokenSource(token string) TokenSource {
+	return TokenSourceFunc(func() (string, error) {
+		return token, nil
+	})
+}
+
+// WithBasicAuth sets the username and password used to authenticate against the schema registry
+func WithBasicAuth(username, password string) Option {
+	return func(options *Options) {
+		options.auth.username = username
+		options.auth.password = password
+	}
+}
+
+// WithBearerTokenSource sets a TokenSource which provides the bearer token attached to each registry request.
+// It takes precedence over WithBasicAuth
+func WithBearerTokenSource(source TokenSource) Option {
+	return func(options *Options) {
+		options.auth.tokenSource = source
+	}
+}
+
+// WithTLSConfig sets the TLS configuration (CA pool, client certificates for mTLS etc.) used to connect to the
+// schema registry
+func WithTLSConfig(config *tls.Config) Option {
+	return func(options *Options) {
+		options.tlsConfig = config
+	}
+}
+
+// WithHTTPClient sets the base http.Client used to connect to the schema registry. Authentication and TLS options
+// are applied on top of a copy of the given client
+func WithHTTPClient(client *http.Client) Option {
+	return func(options *Options) {
+		options.httpClient = client
+	}
+}
+
+// authTransport attaches the configured credentials to each outgoing request
+type authTransport struct {
+	username    string
+	password    string
+	tokenSource TokenSource
+	next        http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	if t.tokenSource != nil {
+		token, err := t.tokenSource.Token()
+		if err != nil {
+			return nil, errors.WithPrevious(err, `fetching bearer token failed`)
+		}
+
+		req.Header.Set(`Authorization`, `Bearer `+token)
+	} else {
+		req.SetBasicAuth(t.username, t.password)
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// newHTTPClient builds the http.Client shared by every registry call using the auth and TLS options
+func newHTTPClient(options *Options) (*http.Client, error) {
+	client := &http.Client{Timeout: defaultHTTPTimeout}
+	if options.httpClient != nil {
+		c := *options.httpClient
+		client = &c
+	}
+
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if options.tlsConfig != nil {
+		httpTransport, ok := transport.(*http.Transport)
+		if !ok {
+			return nil, errors.New(`TLS config cannot be applied, http client transport is not an *http.Transport`)
+		}
+
+		httpTransport = httpTransport.Clone()
+		httpTransport.TLSClientConfig = options.tlsConfig.Clone()
+		transport = httpTransport
+	}
+
+	if options.auth.tokenSource != nil || options.auth.username != `` {
+		transport = &authTransport{
+			username:    options.auth.username,
+			password:    options.auth.password,
+			tokenSource: options.auth.tokenSource,
+			next:        transport,
+		}
+	}
+
+	client.Transport = transport
+
+	return client, nil
+}
@@ -0,0 +1,116 @@
+package schemaregistry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func newAuthCheckServer(t *testing.T, check func(r *http.Request) bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !check(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error_code":401,"message":"unauthorized"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`["test_subject"]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestWithBasicAuth(t *testing.T) {
+	srv := newAuthCheckServer(t, func(r *http.Request) bool {
+		user, pass, ok := r.BasicAuth()
+		return ok && user == `user` && pass == `secret`
+	})
+
+	reg, err := NewRegistry(srv.URL, WithBasicAuth(`user`, `secret`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.client.GetSubjects(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithBearerTokenSource(t *testing.T) {
+	srv := newAuthCheckServer(t, func(r *http.Request) bool {
+		return r.Header.Get(`Authorization`) == `Bearer token-2`
+	})
+
+	var calls int32
+	source := TokenSourceFunc(func() (string, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return `token-1`, nil
+		}
+
+		return `token-2`, nil
+	})
+
+	reg, err := NewRegistry(srv.URL, WithBearerTokenSource(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// first call uses the stale token
+	if _, err := reg.client.GetSubjects(); err == nil {
+		t.Fatal(`expected unauthorized error`)
+	}
+
+	if _, err := reg.client.GetSubjects(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	srv := newAuthCheckServer(t, func(r *http.Request) bool {
+		return r.Header.Get(`X-Custom`) == `yes`
+	})
+
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r.Header.Set(`X-Custom`, `yes`)
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	reg, err := NewRegistry(srv.URL, WithHTTPClient(client))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.client.GetSubjects(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`["test_subject"]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	reg, err := NewRegistry(srv.URL, WithTLSConfig(&tls.Config{RootCAs: pool}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.client.GetSubjects(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
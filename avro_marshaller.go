@@ -9,29 +9,71 @@ package schemaregistry
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/hamba/avro/v2"
 	"github.com/tryfix/errors"
 )
 
+var avroWriterPool = newAvroWriterPool(avro.DefaultConfig)
+
+// newAvroWriterPool returns a pool of avro.Writers using the api
+func newAvroWriterPool(api avro.API) *sync.Pool {
+	return &sync.Pool{
+		New: func() interface{} {
+			return avro.NewWriter(nil, 512, avro.WithWriterConfig(api))
+		},
+	}
+}
+
+// WithAvroConfig sets the avro.Config used to encode and decode avro messages of the Registry (ex: the struct tag
+// key or the max allocation sizes for untrusted input). avro.DefaultConfig is used by default
+func WithAvroConfig(config avro.Config) Option {
+	return func(options *Options) {
+		options.avroAPI = config.Freeze()
+	}
+}
+
 type AvroUnmarshaler struct {
+	api    avro.API
 	schema avro.Schema
 	data   []byte
 }
 
 type AvroMarshaller struct {
 	schema     string
+	references func() ([]string, error) // Referenced schemas in dependency order
 	avroSchema avro.Schema
+	api        avro.API
+	writers    *sync.Pool
 }
 
 func NewAvroMarshaller(schema string) *AvroMarshaller {
 	return &AvroMarshaller{
-		schema: schema,
+		schema:  schema,
+		api:     avro.DefaultConfig,
+		writers: avroWriterPool,
 	}
 }
 
+// Init parses the schema into a schema cache of its own, so named types of other schemas, versions or registries
+// can not leak into it. Referenced schemas are parsed into the same cache first
 func (s *AvroMarshaller) Init() error {
-	schema, err := avro.Parse(s.schema)
+	cache := new(avro.SchemaCache)
+	if s.references != nil {
+		references, err := s.references()
+		if err != nil {
+			return errors.WithPrevious(err, `resolving schema references failed`)
+		}
+
+		for _, reference := range references {
+			if _, err := avro.ParseWithCache(reference, ``, cache); err != nil {
+				return errors.WithPrevious(err, fmt.Sprintf(`schema parsing error for reference %s`, reference))
+			}
+		}
+	}
+
+	schema, err := avro.ParseWithCache(s.schema, ``, cache)
 	if err != nil {
 		return errors.WithPrevious(err, fmt.Sprintf(`schema parsing error for subject %s`, s.schema))
 	}
@@ -40,22 +82,40 @@ func (s *AvroMarshaller) Init() error {
 	return nil
 }
 
+// ParsedSchema returns the parsed avro.Schema, nil before Init is called
+func (s *AvroMarshaller) ParsedSchema() interface{} {
+	return s.avroSchema
+}
+
 func (s *AvroMarshaller) NewUnmarshaler(data []byte) Unmarshaler {
 	return &AvroUnmarshaler{
+		api:    s.api,
 		schema: s.avroSchema,
 		data:   data,
 	}
 }
 
 func (s *AvroUnmarshaler) Unmarshal(in interface{}) error {
-	return avro.Unmarshal(s.schema, s.data, in)
+	return s.api.Unmarshal(s.schema, s.data, in)
 }
 
 func (s *AvroMarshaller) Marshall(data interface{}) ([]byte, error) {
-	native, err := avro.Marshal(s.avroSchema, data)
-	if err != nil {
-		return nil, errors.WithPrevious(err, fmt.Sprintf(`native from textual failed for subject %s`, s.schema))
+	return s.MarshallAppend(nil, data)
+}
+
+// MarshallAppend appends the avro encoded data to dst using a pooled avro.Writer
+func (s *AvroMarshaller) MarshallAppend(dst []byte, data interface{}) ([]byte, error) {
+	writer := s.writers.Get().(*avro.Writer)
+	defer func() {
+		writer.Error = nil
+		s.writers.Put(writer)
+	}()
+
+	writer.Reset(nil)
+	writer.WriteVal(s.avroSchema, data)
+	if writer.Error != nil {
+		return dst, errors.WithPrevious(writer.Error, fmt.Sprintf(`native from textual failed for subject %s`, s.schema))
 	}
 
-	return native, nil
+	return append(dst, writer.Buffer()...), nil
 }
@@ -1 +1,113 @@
 package schemaregistry
+
+import (
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	registry "github.com/riferrei/srclient"
+)
+
+func TestAvroMarshaller_IsolatedSchemaCache(t *testing.T) {
+	v1, v2 := NewAvroMarshaller(testSchemas[`avro_v1`]), NewAvroMarshaller(testSchemas[`avro_v2`])
+	for _, marshaller := range []*AvroMarshaller{v1, v2} {
+		if err := marshaller.Init(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if avro.DefaultSchemaCache.Get(`com.mycorp.mynamespace.SampleRecord`) != nil {
+		t.Error(`need named types not to be added to the default schema cache`)
+	}
+
+	byt, err := v2.Marshall(SampleV2{Field3: `v2`, Field4: `isolated`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := SampleV2{}
+	if err := v2.NewUnmarshaler(byt).Unmarshal(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Field4 != `isolated` {
+		t.Errorf(`need field4 to be decoded, have %+v`, out)
+	}
+}
+
+func TestAvroMarshaller_References(t *testing.T) {
+	marshaller := NewAvroMarshaller(`{
+		"type": "record",
+		"name": "Envelope",
+		"namespace": "com.mycorp.mynamespace",
+		"fields": [{"name": "sample", "type": "com.mycorp.mynamespace.SampleRecord"}]
+	}`)
+
+	if err := marshaller.Init(); err == nil {
+		t.Fatal(`need an error for an unresolved reference`)
+	}
+
+	marshaller.references = func() ([]string, error) {
+		return []string{testSchemas[`avro_v1`]}, nil
+	}
+
+	if err := marshaller.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	type envelope struct {
+		Sample SampleV1 `avro:"sample"`
+	}
+
+	if _, err := marshaller.Marshall(envelope{Sample: SampleV1{Field3: `referenced`}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistry_WithAvroConfig(t *testing.T) {
+	mockClient := registry.CreateMockSchemaRegistryClient(`test`)
+	reg, err := NewRegistry(`mock`, WithMockClient(mockClient), WithAvroConfig(avro.Config{
+		TagKey:           `json`,
+		MaxByteSliceSize: 8,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mockClient.SetSchema(100, `test_subject`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	type sample struct {
+		Field1 int     `json:"field1"`
+		Field2 float64 `json:"field2"`
+		Field3 string  `json:"field3"`
+	}
+
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[sample]()); err != nil {
+		t.Fatal(err)
+	}
+
+	encoder := reg.WithSchema(`test_subject`, 1)
+	byt, err := encoder.Encode(sample{Field1: 1, Field2: 2, Field3: `json`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := encoder.Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out := v.(sample); out.Field1 != 1 || out.Field3 != `json` {
+		t.Errorf(`need fields to be decoded using json tags, have %+v`, out)
+	}
+
+	byt, err = encoder.Encode(sample{Field3: `longer than the max byte slice size`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := encoder.Decode(byt); err == nil {
+		t.Error(`need an error for a string exceeding MaxByteSliceSize`)
+	}
+}
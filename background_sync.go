@@ -3,6 +3,7 @@ package schemaregistry
 import (
 	"fmt"
 	"github.com/tryfix/log"
+	"net/http"
 	"time"
 )
 
@@ -39,57 +40,83 @@ func Sync(syncInterval time.Duration, logger log.Logger, registry *Registry) err
 func (s *backgroundSync) checkRegistryAndAdd() {
 	s.logger.Debug(`Looking for new Schemas...`)
 	added := 0
+	var syncErr error
 	defer func() {
+		s.registry.recordSync(syncErr)
 		s.logger.Debug(fmt.Sprintf(`Looking for new Schemas completed, %d schema/s added`, added))
 	}()
 
 	// Fetch schemas
-	subjects, err := s.registry.client.GetSubjects()
+	subjects, err := s.registry.listSubjects(priorityBackground)
 	if err != nil {
 		s.logger.Error(fmt.Sprintf(`Error getting subjects due to %s`, err.Error()))
+		syncErr = err
 		return
 	}
 
-	// If the subject is registered, check for new versions
 	for _, subjectName := range subjects {
-		if s.registry.subjectRegistered(subjectName) {
-			// Fetch versions
-			versions, err := s.registry.client.GetSchemaVersions(subjectName)
+		// Register new subjects matching a registered pattern
+		if !s.registry.subjectRegistered(subjectName) {
+			pattern, ok := s.registry.matchPattern(subjectName)
+			if !ok || s.registry.isReleased(subjectName, VersionAll) {
+				continue
+			}
+
+			err := s.registry.register(priorityBackground, subjectName, VersionAll, pattern.unmarshalerFunc,
+				pattern.options...)
 			if err != nil {
-				s.logger.Error(fmt.Sprintf(`Error getting schema versions due to %s`, err.Error()))
+				s.logger.Error(fmt.Sprintf(`Registering subject %s matching pattern %s failed due to %s`,
+					subjectName, pattern.pattern, err.Error()))
+				syncErr = err
 				continue
 			}
 
-			for _, version := range versions {
-				if !s.registry.hasVersion(subjectName, Version(version)) {
-					// Fetch versions
-					schema, err := s.registry.client.GetSchemaByVersion(subjectName, version)
-					if err != nil {
-						s.logger.Error(fmt.Sprintf(`Error getting schema by version due to %s`, err.Error()))
-						continue
-					}
-
-					subject := &Subject{
-						Subject:         subjectName,
-						Version:         Version(schema.Version()),
-						Schema:          schema.Schema(),
-						Id:              schema.ID(),
-						UnmarshalerFunc: s.registry.getUnMarshallerFunc(subjectName),
-					}
-
-					if err := s.registry.addSubjectBySchema(schema, subjectName); err != nil {
-						s.logger.Error(fmt.Sprintf("New Schema add failed. [%s:%d] due to %s",
-							subjectName, schema.Version(), err.Error()))
-						continue
-					}
-
-					s.logger.Info(fmt.Sprintf("New Schema registered. %s:%d", subjectName, schema.Version()))
-
-					s.registry.Print(subject)
-					added++
+			s.logger.Info(fmt.Sprintf(`New subject %s registered by pattern %s`, subjectName, pattern.pattern))
+			added++
+			continue
+		}
+
+		// If the subject is registered, check for new versions
+		versions, err := s.registry.syncClient.GetSchemaVersions(subjectName)
+		if err != nil && statusCode(err) == http.StatusNotFound {
+			// Every version of the subject was deleted
+			versions, err = nil, nil
+		}
+
+		if err != nil {
+			s.logger.Error(fmt.Sprintf(`Error getting schema versions due to %s`, err.Error()))
+			syncErr = err
+			continue
+		}
+
+		if err := s.registry.markDeleted(subjectName, versions); err != nil {
+			s.logger.Error(fmt.Sprintf(`Error checking deleted versions of %s due to %s`, subjectName, err.Error()))
+			syncErr = err
+		}
+
+		for _, version := range versions {
+			if !s.registry.hasVersion(subjectName, Version(version)) && !s.registry.isReleased(subjectName, Version(version)) {
+				// Fetch versions
+				schema, err := s.registry.syncClient.GetSchemaByVersion(subjectName, version)
+				if err != nil {
+					s.logger.Error(fmt.Sprintf(`Error getting schema by version due to %s`, err.Error()))
+					syncErr = err
+					continue
 				}
-			}
 
+				subject, err := s.registry.addSubjectBySchema(schema, subjectName, priorityBackground)
+				if err != nil {
+					s.logger.Error(fmt.Sprintf("New Schema add failed. [%s:%d] due to %s",
+						subjectName, schema.Version(), err.Error()))
+					syncErr = err
+					continue
+				}
+
+				s.logger.Info(fmt.Sprintf("New Schema registered. %s:%d", subjectName, schema.Version()))
+
+				s.registry.Print(subject)
+				added++
+			}
 		}
 	}
 }
@@ -0,0 +1,146 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	registry "github.com/riferrei/srclient"
+	com_mycorp_mynamespace "github.com/tryfix/schemaregistry/v2/protobuf"
+)
+
+func setupAvroBenchRegistry(b *testing.B) mockRegistry {
+	b.Helper()
+	reg := setupMockRegistry(1)
+	if _, err := reg.client.SetSchema(100, `test_subject`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		b.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, 1, func(unmarshaler Unmarshaler) (interface{}, error) {
+		v := SampleV1{}
+		if err := unmarshaler.Unmarshal(&v); err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	}); err != nil {
+		b.Fatal(err)
+	}
+
+	return reg
+}
+
+// setupProtoBenchRegistry adds the protobuf subject directly since the mock client cannot parse proto schemas
+func setupProtoBenchRegistry(b *testing.B) mockRegistry {
+	b.Helper()
+	reg := setupMockRegistry(1)
+	subject := &Subject{
+		Schema:  testSchemas[`proto`],
+		Subject: `test_subject_proto`,
+		Version: 1,
+		Id:      200,
+		UnmarshalerFunc: func(unmarshaler Unmarshaler) (interface{}, error) {
+			v := &com_mycorp_mynamespace.SampleRecord{}
+			if err := unmarshaler.Unmarshal(v); err != nil {
+				return nil, err
+			}
+
+			return v, nil
+		},
+		marsheller: NewProtoMarshaller(),
+	}
+
+	reg.subjects[subject.Subject] = map[Version]*Subject{subject.Version: subject}
+	reg.idMap[subject.key()] = subject
+
+	return reg
+}
+
+func BenchmarkAvro_Encode(b *testing.B) {
+	encoder := setupAvroBenchRegistry(b).WithSchema(`test_subject`, 1)
+	v := &SampleV1{Field1: 100, Field2: 10.11, Field3: "text"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := encoder.Encode(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAvro_EncodeAppend(b *testing.B) {
+	encoder := setupAvroBenchRegistry(b).WithSchema(`test_subject`, 1).(AppendEncoder)
+	v := &SampleV1{Field1: 100, Field2: 10.11, Field3: "text"}
+	buf := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = encoder.EncodeAppend(buf[:0], v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAvro_Decode(b *testing.B) {
+	reg := setupAvroBenchRegistry(b)
+	payload, err := reg.WithSchema(`test_subject`, 1).Encode(&SampleV1{Field1: 100, Field2: 10.11, Field3: "text"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	decoder := reg.GenericEncoder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := decoder.Decode(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProto_Encode(b *testing.B) {
+	encoder := setupProtoBenchRegistry(b).WithSchema(`test_subject_proto`, 1)
+	v := &com_mycorp_mynamespace.SampleRecord{Field1: 100, Field2: 10.11, Field3: "text", Field4: "text 2"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := encoder.Encode(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProto_EncodeAppend(b *testing.B) {
+	encoder := setupProtoBenchRegistry(b).WithSchema(`test_subject_proto`, 1).(AppendEncoder)
+	v := &com_mycorp_mynamespace.SampleRecord{Field1: 100, Field2: 10.11, Field3: "text", Field4: "text 2"}
+	buf := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = encoder.EncodeAppend(buf[:0], v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProto_Decode(b *testing.B) {
+	reg := setupProtoBenchRegistry(b)
+	payload, err := reg.WithSchema(`test_subject_proto`, 1).Encode(
+		&com_mycorp_mynamespace.SampleRecord{Field1: 100, Field2: 10.11, Field3: "text", Field4: "text 2"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	decoder := reg.GenericEncoder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := decoder.Decode(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package schemaregistry
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	registry "github.com/riferrei/srclient"
+	"github.com/tryfix/errors"
+	"github.com/tryfix/log"
+)
+
+// FailoverStrategy decides the order in which the registry URLs are tried
+type FailoverStrategy int
+
+const (
+	// FailoverPrimary sends every request to the first healthy URL in the configured order
+	FailoverPrimary FailoverStrategy = iota
+	// FailoverRoundRobin spreads requests across all healthy URLs
+	FailoverRoundRobin
+)
+
+// String returns the strategy name
+func (s FailoverStrategy) String() string {
+	if s == FailoverRoundRobin {
+		return `RoundRobin`
+	}
+
+	return `Primary`
+}
+
+const defaultEndpointCooldown = 30 * time.Second
+
+// WithURLs adds schema registry URLs in addition to the one passed to NewRegistry. A failed call moves on to the
+// next URL
+func WithURLs(urls ...string) Option {
+	return func(options *Options) {
+		options.failover.urls = append(options.failover.urls, urls...)
+	}
+}
+
+// WithFailoverStrategy sets the order in which the registry URLs are tried (default FailoverPrimary)
+func WithFailoverStrategy(strategy FailoverStrategy) Option {
+	return func(options *Options) {
+		options.failover.strategy = strategy
+	}
+}
+
+// WithEndpointCooldown sets how long an unhealthy registry URL is taken out of rotation (default 30s)
+func WithEndpointCooldown(cooldown time.Duration) Option {
+	return func(options *Options) {
+		options.failover.cooldown = cooldown
+	}
+}
+
+// endpointHealth is shared by the endpoints of a registry URL across schema contexts
+type endpointHealth struct {
+	unhealthyUntil time.Time
+}
+
+type endpoint struct {
+	url    string
+	client registry.ISchemaRegistryClient
+	*endpointHealth
+}
+
+// clientState is shared by all registryClient views of a Registry
+type clientState struct {
+	endpoints   []*endpoint
+	strategy    FailoverStrategy
+	cooldown    time.Duration
+	cursor      uint32
+	retryPolicy RetryPolicy
+	breaker     *circuitBreaker
+	limiter     *limiter
+	reachable   *atomic.Bool // Shared with the schema context clients, as are the breaker, limiter and mu
+	mu          *sync.Mutex  // Guards the endpoint health
+	logger      log.Logger
+	newClient   func(url string) registry.ISchemaRegistryClient
+}
+
+// registryClient is the registry.ISchemaRegistryClient used by the Registry. Each call is tried against the
+// configured endpoints until one of them responds
+type registryClient struct {
+	*clientState
+	priority requestPriority
+}
+
+var _ registry.ISchemaRegistryClient = new(registryClient)
+
+func newRegistryClient(urls []string, newClient func(url string) registry.ISchemaRegistryClient, options *Options,
+	logger log.Logger) *registryClient {
+	endpoints := make([]*endpoint, len(urls))
+	for i, u := range urls {
+		endpoints[i] = &endpoint{url: u, client: newClient(u), endpointHealth: new(endpointHealth)}
+	}
+
+	return &registryClient{
+		clientState: &clientState{
+			endpoints:   endpoints,
+			strategy:    options.failover.strategy,
+			cooldown:    options.failover.cooldown,
+			retryPolicy: options.retryPolicy,
+			breaker:     newCircuitBreaker(options.circuitBreaker),
+			limiter:     newLimiter(options.rateLimit),
+			reachable:   new(atomic.Bool),
+			mu:          new(sync.Mutex),
+			logger:      logger,
+			newClient:   newClient,
+		},
+	}
+}
+
+// withContext returns a client whose requests are scoped to the schema context. It shares the rate limits, the
+// circuit breaker and the endpoint and reachability health of c
+func (c *registryClient) withContext(context string) *registryClient {
+	endpoints := make([]*endpoint, len(c.endpoints))
+	for i, ep := range c.endpoints {
+		u := fmt.Sprintf(`%s/contexts/%s`, strings.TrimSuffix(ep.url, `/`), url.PathEscape(context))
+		endpoints[i] = &endpoint{url: u, client: c.newClient(u), endpointHealth: ep.endpointHealth}
+	}
+
+	return &registryClient{
+		clientState: &clientState{
+			endpoints:   endpoints,
+			strategy:    c.strategy,
+			cooldown:    c.cooldown,
+			retryPolicy: c.retryPolicy,
+			breaker:     c.breaker,
+			limiter:     c.limiter,
+			reachable:   c.reachable,
+			mu:          c.mu,
+			logger:      c.logger,
+			newClient:   c.newClient,
+		},
+		priority: c.priority,
+	}
+}
+
+// withPriority returns a view of the client sharing its endpoints, limits and breaker whose requests are sent
+// with the given priority
+func (c *registryClient) withPriority(priority requestPriority) *registryClient {
+	return &registryClient{
+		clientState: c.clientState,
+		priority:    priority,
+	}
+}
+
+// candidates returns the endpoints in the order they should be tried. Unhealthy endpoints are kept at the end
+// so a call is still attempted when all of them are out of rotation
+func (c *registryClient) candidates() []*endpoint {
+	ordered := make([]*endpoint, 0, len(c.endpoints))
+	start := 0
+	if c.strategy == FailoverRoundRobin {
+		start = int((atomic.AddUint32(&c.cursor, 1) - 1) % uint32(len(c.endpoints)))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var unhealthy []*endpoint
+	for i := range c.endpoints {
+		ep := c.endpoints[(start+i)%len(c.endpoints)]
+		if ep.unhealthyUntil.After(now) {
+			unhealthy = append(unhealthy, ep)
+			continue
+		}
+		ordered = append(ordered, ep)
+	}
+
+	return append(ordered, unhealthy...)
+}
+
+func (c *registryClient) markUnhealthy(ep *endpoint, err error) {
+	c.mu.Lock()
+	ep.unhealthyUntil = time.Now().Add(c.cooldown)
+	c.mu.Unlock()
+
+	if len(c.endpoints) > 1 {
+		c.logger.Warn(fmt.Sprintf(`Registry endpoint %s marked unhealthy for %s due to %s`, ep.url, c.cooldown, err))
+	}
+}
+
+func (c *registryClient) markHealthy(ep *endpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ep.unhealthyUntil = time.Time{}
+}
+
+// call runs fn through the circuit breaker, retry policy and endpoint failover
+func call[T any](c *registryClient, fn func(client registry.ISchemaRegistryClient) (T, error)) (T, error) {
+	if !c.breaker.allow() {
+		var res T
+		return res, ErrCircuitOpen
+	}
+
+	res, err := withRetry(c, func() (T, error) {
+		return failover(c, fn)
+	})
+	c.breaker.record(err)
+	c.reachable.Store(err == nil || !isEndpointFailure(err))
+
+	return res, err
+}
+
+// failover tries fn against each endpoint until one of them responds
+func failover[T any](c *registryClient, fn func(client registry.ISchemaRegistryClient) (T, error)) (T, error) {
+	var (
+		res T
+		err error
+	)
+	for _, ep := range c.candidates() {
+		release := c.limiter.acquire(c.priority)
+		res, err = fn(ep.client)
+		release()
+		if err == nil {
+			c.markHealthy(ep)
+			return res, nil
+		}
+
+		if !isEndpointFailure(err) {
+			return res, err
+		}
+
+		c.markUnhealthy(ep, err)
+	}
+
+	return res, err
+}
+
+func (c *registryClient) each(fn func(client registry.ISchemaRegistryClient)) {
+	for _, ep := range c.endpoints {
+		fn(ep.client)
+	}
+}
+
+// statusCode extracts the HTTP status code from a registry error. Zero is returned when the error does not
+// carry one
+func statusCode(err error) int {
+	var regErr registry.Error
+	if stderrors.As(err, &regErr) {
+		// registry error codes are the HTTP status followed by a two digit sub code (ex: 40401)
+		if regErr.Code >= 1000 {
+			return regErr.Code / 100
+		}
+
+		return regErr.Code
+	}
+
+	// srclient falls back to the response status (ex: "502 Bad Gateway") when the body is not a registry error
+	msg := err.Error()
+	if len(msg) >= 3 {
+		if code, convErr := strconv.Atoi(msg[:3]); convErr == nil && (len(msg) == 3 || msg[3] == ' ') {
+			return code
+		}
+	}
+
+	return 0
+}
+
+// isEndpointFailure reports whether the error was caused by the endpoint being unavailable rather than by the
+// request itself
+func isEndpointFailure(err error) bool {
+	if code := statusCode(err); code != 0 {
+		return code >= 500
+	}
+
+	var urlErr *url.Error
+	if stderrors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	var netErr net.Error
+	if stderrors.As(err, &netErr) {
+		return true
+	}
+
+	return stderrors.Is(err, io.EOF) || stderrors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func normalizeURL(u string) string {
+	u = strings.TrimSpace(u)
+	if !(strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")) {
+		u = "http://" + u
+	}
+
+	return u
+}
+
+func (c *registryClient) GetGlobalCompatibilityLevel() (*registry.CompatibilityLevel, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (*registry.CompatibilityLevel, error) {
+		return client.GetGlobalCompatibilityLevel()
+	})
+}
+
+func (c *registryClient) GetCompatibilityLevel(subject string, defaultToGlobal bool) (*registry.CompatibilityLevel, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (*registry.CompatibilityLevel, error) {
+		return client.GetCompatibilityLevel(subject, defaultToGlobal)
+	})
+}
+
+func (c *registryClient) GetSubjects() ([]string, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) ([]string, error) {
+		return client.GetSubjects()
+	})
+}
+
+func (c *registryClient) GetSubjectsIncludingDeleted() ([]string, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) ([]string, error) {
+		return client.GetSubjectsIncludingDeleted()
+	})
+}
+
+func (c *registryClient) GetSchema(schemaID int) (*registry.Schema, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (*registry.Schema, error) {
+		return client.GetSchema(schemaID)
+	})
+}
+
+func (c *registryClient) GetLatestSchema(subject string) (*registry.Schema, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (*registry.Schema, error) {
+		return client.GetLatestSchema(subject)
+	})
+}
+
+func (c *registryClient) GetSchemaVersions(subject string) ([]int, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) ([]int, error) {
+		return client.GetSchemaVersions(subject)
+	})
+}
+
+// GetSchemaVersionsIncludingDeleted lists the versions of the subject including soft deleted ones
+func (c *registryClient) GetSchemaVersionsIncludingDeleted(subject string) ([]int, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) ([]int, error) {
+		lister, ok := client.(deletedVersionsLister)
+		if !ok {
+			return nil, errors.New(`registry client cannot list deleted versions`)
+		}
+
+		return lister.GetSchemaVersionsIncludingDeleted(subject)
+	})
+}
+
+func (c *registryClient) GetSubjectVersionsById(schemaID int) (registry.SubjectVersionResponse, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (registry.SubjectVersionResponse, error) {
+		return client.GetSubjectVersionsById(schemaID)
+	})
+}
+
+func (c *registryClient) GetSchemaByVersion(subject string, version int) (*registry.Schema, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (*registry.Schema, error) {
+		return client.GetSchemaByVersion(subject, version)
+	})
+}
+
+func (c *registryClient) GetSchemaRegistryURL() string {
+	return c.endpoints[0].client.GetSchemaRegistryURL()
+}
+
+func (c *registryClient) CreateSchema(subject string, schema string, schemaType registry.SchemaType,
+	references ...registry.Reference) (*registry.Schema, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (*registry.Schema, error) {
+		return client.CreateSchema(subject, schema, schemaType, references...)
+	})
+}
+
+func (c *registryClient) LookupSchema(subject string, schema string, schemaType registry.SchemaType,
+	references ...registry.Reference) (*registry.Schema, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (*registry.Schema, error) {
+		return client.LookupSchema(subject, schema, schemaType, references...)
+	})
+}
+
+func (c *registryClient) ChangeSubjectCompatibilityLevel(subject string,
+	compatibility registry.CompatibilityLevel) (*registry.CompatibilityLevel, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (*registry.CompatibilityLevel, error) {
+		return client.ChangeSubjectCompatibilityLevel(subject, compatibility)
+	})
+}
+
+func (c *registryClient) DeleteSubject(subject string, permanent bool) error {
+	_, err := call(c, func(client registry.ISchemaRegistryClient) (struct{}, error) {
+		return struct{}{}, client.DeleteSubject(subject, permanent)
+	})
+
+	return err
+}
+
+func (c *registryClient) DeleteSubjectByVersion(subject string, version int, permanent bool) error {
+	_, err := call(c, func(client registry.ISchemaRegistryClient) (struct{}, error) {
+		return struct{}{}, client.DeleteSubjectByVersion(subject, version, permanent)
+	})
+
+	return err
+}
+
+func (c *registryClient) IsSchemaCompatible(subject, schema, version string, schemaType registry.SchemaType,
+	references ...registry.Reference) (bool, error) {
+	return call(c, func(client registry.ISchemaRegistryClient) (bool, error) {
+		return client.IsSchemaCompatible(subject, schema, version, schemaType, references...)
+	})
+}
+
+func (c *registryClient) SetCredentials(username string, password string) {
+	c.each(func(client registry.ISchemaRegistryClient) { client.SetCredentials(username, password) })
+}
+
+func (c *registryClient) SetBearerToken(token string) {
+	c.each(func(client registry.ISchemaRegistryClient) { client.SetBearerToken(token) })
+}
+
+func (c *registryClient) SetTimeout(timeout time.Duration) {
+	c.each(func(client registry.ISchemaRegistryClient) { client.SetTimeout(timeout) })
+}
+
+func (c *registryClient) CachingEnabled(value bool) {
+	c.each(func(client registry.ISchemaRegistryClient) { client.CachingEnabled(value) })
+}
+
+func (c *registryClient) ResetCache() {
+	c.each(func(client registry.ISchemaRegistryClient) { client.ResetCache() })
+}
+
+func (c *registryClient) CodecCreationEnabled(value bool) {
+	c.each(func(client registry.ISchemaRegistryClient) { client.CodecCreationEnabled(value) })
+}
+
+func (c *registryClient) CodecJsonEnabled(value bool) {
+	c.each(func(client registry.ISchemaRegistryClient) { client.CodecJsonEnabled(value) })
+}
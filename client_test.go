@@ -0,0 +1,88 @@
+package schemaregistry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCountingServer(t *testing.T, status int) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error_code":50301,"message":"unavailable"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`["test_subject"]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &hits
+}
+
+func TestRegistryClient_Failover(t *testing.T) {
+	down, downHits := newCountingServer(t, http.StatusServiceUnavailable)
+	up, upHits := newCountingServer(t, http.StatusOK)
+
+	reg, err := NewRegistry(down.URL+`,`+up.URL, WithEndpointCooldown(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := reg.client.GetSubjects(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the failed endpoint is only tried once and then taken out of rotation
+	if have := atomic.LoadInt32(downHits); have != 1 {
+		t.Errorf(`need 1 call to the unhealthy endpoint, have %d`, have)
+	}
+
+	if have := atomic.LoadInt32(upHits); have != 3 {
+		t.Errorf(`need 3 calls to the healthy endpoint, have %d`, have)
+	}
+}
+
+func TestRegistryClient_FailoverUnreachable(t *testing.T) {
+	down, _ := newCountingServer(t, http.StatusOK)
+	down.Close()
+	up, _ := newCountingServer(t, http.StatusOK)
+
+	reg, err := NewRegistry(down.URL, WithURLs(up.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.client.GetSubjects(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistryClient_RoundRobin(t *testing.T) {
+	first, firstHits := newCountingServer(t, http.StatusOK)
+	second, secondHits := newCountingServer(t, http.StatusOK)
+
+	reg, err := NewRegistry(first.URL, WithURLs(second.URL), WithFailoverStrategy(FailoverRoundRobin))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := reg.client.GetSubjects(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if atomic.LoadInt32(firstHits) != 2 || atomic.LoadInt32(secondHits) != 2 {
+		t.Errorf(`need requests to be spread evenly, have %d and %d`,
+			atomic.LoadInt32(firstHits), atomic.LoadInt32(secondHits))
+	}
+}
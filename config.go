@@ -0,0 +1,183 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	registry "github.com/riferrei/srclient"
+	"github.com/tryfix/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// TypeRegistry binds Go type names used in config files to their UnmarshalerFuncs
+type TypeRegistry map[string]UnmarshalerFunc
+
+// BindType adds the UnmarshalerFunc of T to the TypeRegistry under the name
+func BindType[T any](types TypeRegistry, name string) {
+	types[name] = NewUnmarshalerFunc[T]()
+}
+
+// SubjectConfig declares the versions of a subject to register. Versions is all (default), latest, an exact version
+// or a version range (see ParseVersionRange). Versions later added within a range use the same type
+type SubjectConfig struct {
+	Subject    string `json:"subject" yaml:"subject"`
+	Versions   string `json:"versions" yaml:"versions"`
+	SchemaType string `json:"schemaType" yaml:"schemaType"` // Optional, registration fails when the schema type differs
+	Type       string `json:"type" yaml:"type"`             // Name of the type in the TypeRegistry
+}
+
+// RegistryConfig is the content of a registration config file
+type RegistryConfig struct {
+	Subjects []SubjectConfig `json:"subjects" yaml:"subjects"`
+}
+
+// ConfigError holds every error of a RegisterFromConfig call
+type ConfigError struct {
+	File   string
+	Errors []error
+}
+
+func (e *ConfigError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("registering subjects from %s failed:\n%s", e.File, strings.Join(msgs, "\n"))
+}
+
+func (e *ConfigError) Unwrap() []error {
+	return e.Errors
+}
+
+// RegisterFromConfig registers the subjects declared in a YAML (.yaml, .yml) or JSON (.json) file. Every entry is
+// registered even when others fail, the failures are returned together as a *ConfigError
+func RegisterFromConfig(reg *Registry, file string, types TypeRegistry) error {
+	byt, err := os.ReadFile(file)
+	if err != nil {
+		return errors.WithPrevious(err, fmt.Sprintf(`reading config %s failed`, file))
+	}
+
+	config := new(RegistryConfig)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case `.yaml`, `.yml`:
+		err = yaml.Unmarshal(byt, config)
+	case `.json`:
+		err = json.Unmarshal(byt, config)
+	default:
+		return errors.New(fmt.Sprintf(`unsupported config format [%s]`, filepath.Ext(file)))
+	}
+
+	if err != nil {
+		return errors.WithPrevious(err, fmt.Sprintf(`parsing config %s failed`, file))
+	}
+
+	var errs []error
+	for i, entry := range config.Subjects {
+		if err := reg.registerConfig(entry, types); err != nil {
+			errs = append(errs, errors.WithPrevious(err, fmt.Sprintf(`subjects[%d] %s:%s`, i, entry.Subject,
+				entry.Versions)))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ConfigError{File: file, Errors: errs}
+	}
+
+	return nil
+}
+
+func (r *Registry) registerConfig(entry SubjectConfig, types TypeRegistry) error {
+	if entry.Subject == `` {
+		return errors.New(`subject is required`)
+	}
+
+	unmarshalerFunc, ok := types[entry.Type]
+	if !ok {
+		return errors.New(fmt.Sprintf(`type [%s] is not bound in the TypeRegistry`, entry.Type))
+	}
+
+	subject := r.qualify(entry.Subject)
+	listed, err := r.client.GetSchemaVersions(subject)
+	if err != nil {
+		return errors.WithPrevious(err, `fetching schema versions failed`)
+	}
+
+	versions, isRange, err := selectVersions(entry.Versions, listed)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		return errors.New(`no versions of the subject match`)
+	}
+
+	if entry.SchemaType != `` {
+		schema, err := r.client.GetSchemaByVersion(subject, int(versions[0]))
+		if err != nil {
+			return errors.WithPrevious(err, `fetching schema failed`)
+		}
+
+		schemaType := registry.Avro
+		if schema.SchemaType() != nil {
+			schemaType = *schema.SchemaType()
+		}
+
+		if !strings.EqualFold(string(schemaType), entry.SchemaType) {
+			return errors.New(fmt.Sprintf(`subject is of schema type %s, not %s`, schemaType, entry.SchemaType))
+		}
+	}
+
+	for _, version := range versions {
+		if err := r.Register(subject, version, unmarshalerFunc); err != nil {
+			return err
+		}
+	}
+
+	if isRange {
+		return r.RegisterUnmarshaler(subject, entry.Versions, unmarshalerFunc)
+	}
+
+	return nil
+}
+
+// selectVersions returns the listed versions selected by the versions expression and whether it is a range
+func selectVersions(expr string, listed []int) ([]Version, bool, error) {
+	var selected []Version
+	switch strings.ToLower(strings.TrimSpace(expr)) {
+	case ``, `all`:
+		for _, v := range listed {
+			selected = append(selected, Version(v))
+		}
+		return selected, false, nil
+	case `latest`:
+		var latest Version
+		for _, v := range listed {
+			if Version(v) > latest {
+				latest = Version(v)
+			}
+		}
+
+		if latest == 0 {
+			return nil, false, nil
+		}
+
+		return []Version{latest}, false, nil
+	}
+
+	rng, err := ParseVersionRange(expr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, v := range listed {
+		if rng.Contains(Version(v)) {
+			selected = append(selected, Version(v))
+		}
+	}
+
+	return selected, rng.Min != rng.Max, nil
+}
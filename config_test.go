@@ -0,0 +1,77 @@
+package schemaregistry
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisterFromConfig(t *testing.T) {
+	configs := map[string]string{
+		`registry.yaml`: `
+subjects:
+  - subject: test_subject
+    versions: 1
+    schemaType: AVRO
+    type: SampleV1
+  - subject: test_subject
+    versions: '>=2'
+    type: SampleV2
+  - subject: test_subject
+    type: Unknown
+  - subject: unknown_subject
+    type: SampleV1
+  - subject: test_subject
+    schemaType: PROTOBUF
+    type: SampleV1
+`,
+		`registry.json`: `{"subjects": [
+  {"subject": "test_subject", "versions": "1", "schemaType": "AVRO", "type": "SampleV1"},
+  {"subject": "test_subject", "versions": ">=2", "type": "SampleV2"},
+  {"subject": "test_subject", "type": "Unknown"},
+  {"subject": "unknown_subject", "type": "SampleV1"},
+  {"subject": "test_subject", "schemaType": "PROTOBUF", "type": "SampleV1"}
+]}`,
+	}
+
+	types := TypeRegistry{}
+	BindType[SampleV1](types, `SampleV1`)
+	BindType[SampleV2](types, `SampleV2`)
+
+	for name, content := range configs {
+		t.Run(name, func(t *testing.T) {
+			reg := setupSampleRegistry(t)
+
+			file := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var configErr *ConfigError
+			if err := RegisterFromConfig(reg.Registry, file, types); !stderrors.As(err, &configErr) {
+				t.Fatalf(`need a ConfigError, have %v`, err)
+			}
+
+			if len(configErr.Errors) != 3 {
+				t.Errorf(`need 3 errors, have %s`, configErr)
+			}
+
+			for version, want := range map[Version]interface{}{1: SampleV1{}, 2: SampleV2{}} {
+				byt, err := reg.WithSchema(`test_subject`, version).Encode(want)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				v, err := reg.GenericEncoder().Decode(byt)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if _, ok := v.(SampleV1); ok != (version == 1) {
+					t.Errorf(`unexpected type %T decoded for version %d`, v, version)
+				}
+			}
+		})
+	}
+}
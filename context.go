@@ -0,0 +1,128 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	registry "github.com/riferrei/srclient"
+)
+
+// DefaultContext is the registry's default schema context, subjects in it are not qualified
+const DefaultContext = `.`
+
+// schemaKey identifies a schema ID within a schema context, IDs are only unique within their context
+type schemaKey struct {
+	context string
+	id      int
+}
+
+// WithSchemaContext sets the schema context of the Registry. Unqualified subject names are qualified with it
+// (ex: :.tenant:subject) and schema IDs of decoded messages are resolved in it
+func WithSchemaContext(context string) Option {
+	return func(options *Options) {
+		options.schemaContext = normalizeContext(context)
+	}
+}
+
+// QualifySubject returns the subject name qualified with the context (ex: :.tenant:subject). Subjects of the
+// default context and subjects which are already qualified are returned as is
+func QualifySubject(context, subject string) string {
+	context = normalizeContext(context)
+	if context == `` || strings.HasPrefix(subject, `:.`) {
+		return subject
+	}
+
+	return fmt.Sprintf(`:%s:%s`, context, subject)
+}
+
+// SplitSubject splits a qualified subject name into its context and subject. The context is empty for subjects of
+// the default context
+func SplitSubject(subject string) (context, name string) {
+	if !strings.HasPrefix(subject, `:.`) {
+		return ``, subject
+	}
+
+	i := strings.Index(subject[1:], `:`)
+	if i < 0 {
+		return ``, subject
+	}
+
+	return normalizeContext(subject[1 : i+1]), subject[i+2:]
+}
+
+// normalizeContext returns the context name with its leading dot, the default context is returned as empty
+func normalizeContext(context string) string {
+	context = strings.TrimSpace(context)
+	if context == `` || context == DefaultContext {
+		return ``
+	}
+
+	if !strings.HasPrefix(context, `.`) {
+		context = `.` + context
+	}
+
+	return context
+}
+
+// ContextEncoder returns a placeholder encoder for decoders which resolves schema IDs in the given context
+func (r *Registry) ContextEncoder(context string) Encoder {
+	return &GenericEncoder{&RegistryEncoder{registry: r, upcast: true, schemaContext: normalizeContext(context)}}
+}
+
+// qualify qualifies unqualified subject names with the Registry's schema context
+func (r *Registry) qualify(subject string) string {
+	return QualifySubject(r.options.schemaContext, subject)
+}
+
+// clientFor returns the client scoped to the schema context sending requests with the given priority, context
+// clients are created on first use
+func (r *Registry) clientFor(context string, priority requestPriority) registry.ISchemaRegistryClient {
+	client, ok := r.client.(*registryClient)
+	if !ok {
+		return r.client
+	}
+
+	if context == `` {
+		return client.withPriority(priority)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.contextClients[context]
+	if !ok {
+		c = client.withContext(context)
+		r.contextClients[context] = c
+	}
+
+	return c.withPriority(priority)
+}
+
+// listSubjects returns the subjects of the Registry's schema context qualified with it, followed by the registered
+// subjects it does not list. Those are subjects of other contexts or subjects deleted in the registry
+func (r *Registry) listSubjects(priority requestPriority) ([]string, error) {
+	subjects, err := r.clientFor(r.options.schemaContext, priority).GetSubjects()
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make(map[string]bool, len(subjects))
+	for i := range subjects {
+		subjects[i] = r.qualify(subjects[i])
+		listed[subjects[i]] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var unlisted []string
+	for subject := range r.subjects {
+		if !listed[subject] {
+			unlisted = append(unlisted, subject)
+		}
+	}
+	sort.Strings(unlisted)
+
+	return append(subjects, unlisted...), nil
+}
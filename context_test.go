@@ -0,0 +1,150 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	registry "github.com/riferrei/srclient"
+)
+
+func TestQualifySubject(t *testing.T) {
+	tests := []struct {
+		context, subject, qualified string
+	}{
+		{``, `test_subject`, `test_subject`},
+		{`.`, `test_subject`, `test_subject`},
+		{`tenant`, `test_subject`, `:.tenant:test_subject`},
+		{`.tenant`, `test_subject`, `:.tenant:test_subject`},
+		{`.tenant`, `:.other:test_subject`, `:.other:test_subject`},
+	}
+
+	for _, test := range tests {
+		qualified := QualifySubject(test.context, test.subject)
+		if qualified != test.qualified {
+			t.Errorf(`need %s, have %s`, test.qualified, qualified)
+		}
+
+		context, subject := SplitSubject(qualified)
+		if subject != `test_subject` || (test.qualified != `test_subject` && context == ``) {
+			t.Errorf(`unexpected split of %s into %s, %s`, qualified, context, subject)
+		}
+	}
+}
+
+func TestRegistry_SchemaContextIDs(t *testing.T) {
+	reg := setupSampleRegistry(t)
+
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same ID is used by a different schema in the tenant context
+	if _, err := reg.client.SetSchema(100, `:.tenant:test_subject`, testSchemas[`avro_v2`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`:.tenant:test_subject`, 1, NewUnmarshalerFunc[SampleV2]()); err != nil {
+		t.Fatal(err)
+	}
+
+	byt, err := reg.WithSchema(`:.tenant:test_subject`, 1).Encode(SampleV2{Field1: 1, Field4: `tenant`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := reg.ContextEncoder(`.tenant`).Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sample, ok := v.(SampleV2); !ok || sample.Field4 != `tenant` {
+		t.Errorf(`unexpected decoded value %+v`, v)
+	}
+
+	byt, err = reg.WithSchema(`test_subject`, 1).Encode(SampleV1{Field1: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = reg.GenericEncoder().Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := v.(SampleV1); !ok {
+		t.Errorf(`need SampleV1, have %T`, v)
+	}
+
+	if reg.Health().CachedIDs != 2 {
+		t.Errorf(`need 2 cached IDs, have %d`, reg.Health().CachedIDs)
+	}
+}
+
+func TestRegistry_WithSchemaContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case `/contexts/.tenant/schemas/ids/5`:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{`schema`: testSchemas[`avro_v1`]})
+		case `/contexts/.tenant/schemas/ids/5/versions`:
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{{`subject`: `test_subject`, `version`: 1}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	reg, err := NewRegistry(srv.URL, WithSchemaContext(`tenant`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoder, err := reg.WithSchemaID(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := encoder.(*RegistryEncoder).subject.snapshot()
+	if snapshot.Subject != `:.tenant:test_subject` || snapshot.Context != `.tenant` {
+		t.Errorf(`unexpected snapshot %s in context %s`, snapshot.Subject, snapshot.Context)
+	}
+}
+
+func TestRegistry_WithSchemaContextReferences(t *testing.T) {
+	envelope := `{
+		"type": "record",
+		"name": "Envelope",
+		"namespace": "com.mycorp.mynamespace",
+		"fields": [{"name": "sample", "type": "com.mycorp.mynamespace.SampleRecord"}]
+	}`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case `/contexts/.tenant/schemas/ids/6`:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{`schema`: envelope, `references`: []map[string]interface{}{
+				{`name`: `com.mycorp.mynamespace.SampleRecord`, `subject`: `sample_record`, `version`: 1},
+			}})
+		case `/contexts/.tenant/schemas/ids/6/versions`:
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{{`subject`: `envelope`, `version`: 1}})
+		case `/contexts/.tenant/subjects/sample_record/versions/1`:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				`subject`: `sample_record`, `version`: 1, `id`: 5, `schema`: testSchemas[`avro_v1`]})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	reg, err := NewRegistry(srv.URL, WithSchemaContext(`tenant`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The reference is resolved in the tenant context, not in the default one
+	if _, err := reg.WithSchemaID(6); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,208 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	registry "github.com/riferrei/srclient"
+	"github.com/tryfix/errors"
+)
+
+// DeletedState tells whether a cached version was deleted in the registry
+type DeletedState int32
+
+const (
+	NotDeleted DeletedState = iota
+	// SoftDeleted versions are only listed under the subject with deleted=true
+	SoftDeleted
+	// HardDeleted versions were permanently removed from the registry
+	HardDeleted
+)
+
+// String returns the state name
+func (d DeletedState) String() string {
+	switch d {
+	case SoftDeleted:
+		return `SoftDeleted`
+	case HardDeleted:
+		return `HardDeleted`
+	}
+
+	return `NotDeleted`
+}
+
+// DeletedPolicy decides how encoders treat versions deleted in the registry
+type DeletedPolicy int
+
+const (
+	// DeletedAllow keeps using deleted versions
+	DeletedAllow DeletedPolicy = iota
+	// DeletedWarn keeps using deleted versions and logs a warning the first time one is used
+	DeletedWarn
+	// DeletedRefuse fails with a *DeletedVersionError
+	DeletedRefuse
+)
+
+// WithDeletedVersionPolicy sets how decoding and encoding of versions the background sync found deleted in the
+// registry is handled (default DeletedAllow for both)
+func WithDeletedVersionPolicy(decode, encode DeletedPolicy) Option {
+	return func(options *Options) {
+		options.deletedPolicy.decode = decode
+		options.deletedPolicy.encode = encode
+	}
+}
+
+// DeletedVersionError is returned when the DeletedPolicy refuses to use a deleted version
+type DeletedVersionError struct {
+	Subject string
+	Version Version
+	State   DeletedState
+}
+
+func (e *DeletedVersionError) Error() string {
+	return fmt.Sprintf(`subject %s:%s is %s in the registry`, e.Subject, e.Version, e.State)
+}
+
+// DeletedState returns whether the version was found deleted in the registry by the background sync
+func (s *Subject) DeletedState() DeletedState {
+	return DeletedState(atomic.LoadInt32(&s.deleted))
+}
+
+// checkDeleted applies the policy to the subject, op names the operation in the warning
+func (r *Registry) checkDeleted(subject *Subject, policy DeletedPolicy, op string) error {
+	state := subject.DeletedState()
+	if state == NotDeleted || policy == DeletedAllow {
+		return nil
+	}
+
+	if policy == DeletedRefuse {
+		return &DeletedVersionError{Subject: subject.Subject, Version: subject.Version, State: state}
+	}
+
+	if atomic.CompareAndSwapInt32(&subject.deletedWarned, 0, 1) {
+		r.logger.Warn(fmt.Sprintf(`%s using %s which is %s in the registry`, op, subject, state))
+	}
+
+	return nil
+}
+
+// markDeleted updates the DeletedState of the cached versions of the subject. Cached versions missing from the
+// listed versions are soft deleted when the registry still lists them with deleted=true and hard deleted otherwise.
+// The registry is only asked again once the listed versions change
+func (r *Registry) markDeleted(subject string, listed []int) error {
+	live := make(map[Version]bool, len(listed))
+	for _, v := range listed {
+		live[Version(v)] = true
+	}
+
+	key := fmt.Sprint(listed)
+
+	r.mu.RLock()
+	var cached []*Subject
+	missing := false
+	for _, s := range r.subjects[subject] {
+		cached = append(cached, s)
+		missing = missing || !live[s.Version]
+	}
+	checked := r.deletedChecks[subject] == key
+	r.mu.RUnlock()
+
+	if missing && checked {
+		return nil
+	}
+
+	var softDeleted map[Version]bool
+	if missing {
+		all, err := r.listVersionsIncludingDeleted(subject)
+		if err != nil {
+			return err
+		}
+
+		softDeleted = make(map[Version]bool, len(all))
+		for _, v := range all {
+			softDeleted[Version(v)] = !live[Version(v)]
+		}
+	}
+
+	for _, s := range cached {
+		state := NotDeleted
+		if !live[s.Version] {
+			state = HardDeleted
+			if softDeleted[s.Version] {
+				state = SoftDeleted
+			}
+		}
+
+		if previous := DeletedState(atomic.SwapInt32(&s.deleted, int32(state))); previous != state {
+			atomic.StoreInt32(&s.deletedWarned, 0)
+			r.logger.Warn(fmt.Sprintf(`Subject %s changed from %s to %s in the registry`, s, previous, state))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subjects[subject]; ok {
+		r.deletedChecks[subject] = key
+	}
+
+	return nil
+}
+
+// listVersionsIncludingDeleted lists the versions of the subject including the soft deleted ones, none are returned
+// when the subject was hard deleted
+func (r *Registry) listVersionsIncludingDeleted(subject string) ([]int, error) {
+	lister, ok := r.syncClient.(deletedVersionsLister)
+	if !ok {
+		return nil, errors.New(`registry client cannot list deleted versions`)
+	}
+
+	versions, err := lister.GetSchemaVersionsIncludingDeleted(subject)
+	if err != nil && statusCode(err) == http.StatusNotFound {
+		return nil, nil
+	}
+
+	return versions, err
+}
+
+// deletedVersionsLister is implemented by registry clients which list the versions of a subject including soft
+// deleted ones (GET /subjects/{subject}/versions?deleted=true)
+type deletedVersionsLister interface {
+	GetSchemaVersionsIncludingDeleted(subject string) ([]int, error)
+}
+
+// versionsClient adds listing deleted versions to the srclient client, which does not support it
+type versionsClient struct {
+	*registry.SchemaRegistryClient
+	url  string
+	http *http.Client
+}
+
+func (c *versionsClient) GetSchemaVersionsIncludingDeleted(subject string) ([]int, error) {
+	u := fmt.Sprintf(`%s/subjects/%s/versions?deleted=true`, strings.TrimSuffix(c.url, `/`), url.PathEscape(subject))
+	resp, err := c.http.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		regErr := registry.Error{}
+		if err := json.NewDecoder(resp.Body).Decode(&regErr); err != nil || regErr.Code == 0 {
+			regErr = registry.Error{Code: resp.StatusCode, Message: resp.Status}
+		}
+
+		return nil, regErr
+	}
+
+	var versions []int
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return nil, errors.WithPrevious(err, fmt.Sprintf(`decoding versions of %s failed`, subject))
+	}
+
+	return versions, nil
+}
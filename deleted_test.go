@@ -0,0 +1,163 @@
+package schemaregistry
+
+import (
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	registry "github.com/riferrei/srclient"
+	"github.com/tryfix/log"
+)
+
+// softDeleteClient keeps listing versions deleted without permanent with deleted=true
+type softDeleteClient struct {
+	*registry.MockSchemaRegistryClient
+	softDeleted map[string][]int
+	listings    int
+}
+
+func newSoftDeleteClient(reg mockRegistry) *softDeleteClient {
+	client := &softDeleteClient{MockSchemaRegistryClient: reg.client, softDeleted: map[string][]int{}}
+	reg.Registry.client.(*registryClient).endpoints[0].client = client
+
+	return client
+}
+
+func (c *softDeleteClient) DeleteSubject(subject string, permanent bool) error {
+	if !permanent {
+		versions, _ := c.GetSchemaVersions(subject)
+		c.softDeleted[subject] = append(c.softDeleted[subject], versions...)
+	}
+
+	return c.MockSchemaRegistryClient.DeleteSubject(subject, permanent)
+}
+
+func (c *softDeleteClient) DeleteSubjectByVersion(subject string, version int, permanent bool) error {
+	if !permanent {
+		c.softDeleted[subject] = append(c.softDeleted[subject], version)
+	}
+
+	return c.MockSchemaRegistryClient.DeleteSubjectByVersion(subject, version, permanent)
+}
+
+func (c *softDeleteClient) GetSchemaVersionsIncludingDeleted(subject string) ([]int, error) {
+	c.listings++
+	versions, _ := c.GetSchemaVersions(subject)
+	versions = append(versions, c.softDeleted[subject]...)
+	if len(versions) == 0 {
+		return nil, registry.Error{Code: 40401, Message: `Subject not found`}
+	}
+
+	return versions, nil
+}
+
+func TestRegistry_DeletedVersions(t *testing.T) {
+	reg := setupSampleRegistry(t, WithDeletedVersionPolicy(DeletedWarn, DeletedRefuse))
+	client := newSoftDeleteClient(reg)
+
+	if err := reg.Register(`test_subject`, VersionAll, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	byt, err := reg.WithSchema(`test_subject`, 1).Encode(SampleV1{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Version 1 is soft deleted, version 2 is hard deleted while its schema ID still resolves
+	if err := client.DeleteSubjectByVersion(`test_subject`, 1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.DeleteSubjectByVersion(`test_subject`, 2, true); err != nil {
+		t.Fatal(err)
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+	sync.checkRegistryAndAdd()
+
+	if health := reg.Health(); health.LastSyncError != nil {
+		t.Fatal(health.LastSyncError)
+	}
+
+	if client.listings != 1 {
+		t.Errorf(`need deleted versions to be listed once until the versions change, have %d listings`, client.listings)
+	}
+
+	for version, want := range map[Version]DeletedState{1: SoftDeleted, 2: HardDeleted} {
+		snapshot := reg.Versions(`test_subject`)[version-1]
+		if snapshot.Deleted != want {
+			t.Errorf(`need version %d to be %s, have %s`, version, want, snapshot.Deleted)
+		}
+	}
+
+	if _, err := reg.GenericEncoder().Decode(byt); err != nil {
+		t.Errorf(`need decoding deleted versions to be allowed with a warning, have %s`, err)
+	}
+
+	var deletedErr *DeletedVersionError
+	_, err = reg.WithSchema(`test_subject`, 1).Encode(SampleV1{})
+	if !stderrors.As(err, &deletedErr) || deletedErr.State != SoftDeleted {
+		t.Errorf(`need a DeletedVersionError, have %v`, err)
+	}
+}
+
+func TestRegistry_DeletedSubject(t *testing.T) {
+	reg := setupSampleRegistry(t, WithDeletedVersionPolicy(DeletedRefuse, DeletedRefuse))
+	client := newSoftDeleteClient(reg)
+
+	if err := reg.Register(`test_subject`, VersionAll, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.DeleteSubject(`test_subject`, true); err != nil {
+		t.Fatal(err)
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	if health := reg.Health(); health.LastSyncError != nil {
+		t.Fatal(health.LastSyncError)
+	}
+
+	for _, snapshot := range reg.Versions(`test_subject`) {
+		if snapshot.Deleted != HardDeleted {
+			t.Errorf(`need version %d of the deleted subject to be HardDeleted, have %s`, snapshot.Version, snapshot.Deleted)
+		}
+	}
+
+	var deletedErr *DeletedVersionError
+	if _, err := reg.WithSchema(`test_subject`, 1).Encode(SampleV1{}); !stderrors.As(err, &deletedErr) {
+		t.Errorf(`need a DeletedVersionError, have %v`, err)
+	}
+}
+
+func TestVersionsClient_GetSchemaVersionsIncludingDeleted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != `/subjects/test_subject/versions` || r.URL.Query().Get(`deleted`) != `true` {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`[1,2]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	reg, err := NewRegistry(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := reg.listVersionsIncludingDeleted(`test_subject`)
+	if err != nil || len(versions) != 2 {
+		t.Errorf(`need versions 1 and 2, have %v, %v`, versions, err)
+	}
+
+	if versions, err := reg.listVersionsIncludingDeleted(`other_subject`); err != nil || versions != nil {
+		t.Errorf(`need no versions for a hard deleted subject, have %v, %v`, versions, err)
+	}
+}
@@ -10,6 +10,7 @@ package schemaregistry
 import (
 	"encoding/binary"
 	"fmt"
+	"sync"
 
 	"github.com/tryfix/errors"
 )
@@ -29,24 +30,54 @@ type Unmarshaler interface {
 	Unmarshal(in interface{}) error
 }
 
+// AppendEncoder is implemented by encoders which can write the encoded message into a caller supplied buffer.
+// Encoders returned by Registry.WithSchema and Registry.WithLatestSchema implement it
+type AppendEncoder interface {
+	EncodeAppend(dst []byte, v interface{}) ([]byte, error)
+}
+
+// AppendMarshaller is implemented by Marshallers which can append the encoded data to a caller supplied buffer.
+// Marshallers without it fall back to Marshall and an extra copy
+type AppendMarshaller interface {
+	MarshallAppend(dst []byte, v interface{}) ([]byte, error)
+}
+
+const prefixLen = 5
+
+var encodeBufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
 // RegistryEncoder holds the reference to Registry and Subject which can be used to encode and decode messages
 type RegistryEncoder struct {
 	subject  *Subject
 	registry *Registry
+	upcast   bool // apply migrations on decode
+	// Schema context the IDs of decoded messages are resolved in
+	schemaContext string
 }
 
 // NewRegistryEncoder NewEncoder return the Encoder for given Subject from the Registry
 func NewRegistryEncoder(registry *Registry, subject *Subject) Encoder {
+	context := registry.options.schemaContext
+	if subject != nil {
+		context = subject.context
+	}
+
 	return &RegistryEncoder{
-		subject:  subject,
-		registry: registry,
+		subject:       subject,
+		registry:      registry,
+		schemaContext: context,
 	}
 }
 
-func encodePrefix(id int) []byte {
-	byt := make([]byte, 5)
-	binary.BigEndian.PutUint32(byt[1:], uint32(id))
-	return byt
+func appendPrefix(dst []byte, id int) []byte {
+	dst = append(dst, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(dst[len(dst)-4:], uint32(id))
+	return dst
 }
 
 // Encode return a byte slice with a avro encoded message. magic byte and schema id will be appended to its beginning
@@ -55,28 +86,63 @@ func encodePrefix(id int) []byte {
 //	║ magic byte(1 byte) │ schema id(4 bytes) │ Encoded Message		   ║
 //	╚════════════════════╧════════════════════╧════════════════════════╝
 func (s *RegistryEncoder) Encode(data interface{}) ([]byte, error) {
-	encoded, err := s.subject.marsheller.Marshall(data)
+	buf := encodeBufferPool.Get().(*[]byte)
+	defer encodeBufferPool.Put(buf)
+
+	encoded, err := s.EncodeAppend((*buf)[:0], data)
 	if err != nil {
 		return nil, err
 	}
+	*buf = encoded
+
+	out := make([]byte, len(encoded))
+	copy(out, encoded)
+
+	return out, nil
+}
+
+// EncodeAppend appends the magic byte, schema id and the encoded message to dst and returns the extended buffer.
+// No allocations are made when dst has enough capacity and the Marshaller implements AppendMarshaller
+func (s *RegistryEncoder) EncodeAppend(dst []byte, data interface{}) ([]byte, error) {
+	if err := s.registry.checkDeleted(s.subject, s.registry.options.deletedPolicy.encode, `Encoding`); err != nil {
+		return dst, err
+	}
 
-	return append(encodePrefix(s.subject.Id), encoded...), nil
+	start := len(dst)
+	dst = appendPrefix(dst, s.subject.Id)
+
+	if marshaller, ok := s.subject.marsheller.(AppendMarshaller); ok {
+		out, err := marshaller.MarshallAppend(dst, data)
+		if err != nil {
+			return dst[:start], err
+		}
+
+		return out, nil
+	}
+
+	encoded, err := s.subject.marsheller.Marshall(data)
+	if err != nil {
+		return dst[:start], err
+	}
+
+	return append(dst, encoded...), nil
 }
 
-// Decode returns the decoded go interface of avro encoded message and error if its unable to decode
+// Decode returns the decoded go interface of avro encoded message and error if its unable to decode. Encoders
+// returned by GenericEncoder and ContextEncoder apply the migrations registered for the subject to the decoded value
 func (s *RegistryEncoder) Decode(data []byte) (interface{}, error) {
-	if len(data) < 5 {
+	if len(data) < prefixLen {
 		return nil, errors.New(`message length is zero`)
 	}
 
 	schemaID := int(binary.BigEndian.Uint32((data)[1:5]))
 
 GetSubject:
-	subject, ok := s.registry.getSubjectBySchemaID(schemaID)
+	subject, ok := s.registry.getSubjectBySchemaID(s.schemaContext, schemaID)
 	if !ok {
 		s.registry.logger.Warn(
 			fmt.Sprintf(`Schema id [%d] dose not registred. Fetching from Schema registry`, schemaID))
-		if err := s.registry.updateRegistryCache(schemaID); err != nil {
+		if err := s.registry.updateRegistryCache(s.schemaContext, schemaID); err != nil {
 			s.registry.logger.Error(
 				fmt.Sprintf(`Registry update failed for schema ID [%d] due to %s`, schemaID, err))
 		} else {
@@ -86,5 +152,19 @@ GetSubject:
 		return nil, errors.New(fmt.Sprintf(`schema id [%d] dose not registred`, schemaID))
 	}
 
-	return subject.UnmarshalerFunc(subject.marsheller.NewUnmarshaler(data[5:]))
+	if err := s.registry.checkDeleted(subject, s.registry.options.deletedPolicy.decode, `Decoding`); err != nil {
+		return nil, err
+	}
+
+	if subject.UnmarshalerFunc == nil {
+		return nil, errors.New(fmt.Sprintf(`subject %s is not registered, no UnmarshalerFunc to decode schema id [%d]`,
+			subject.Subject, schemaID))
+	}
+
+	v, err := subject.UnmarshalerFunc(subject.marsheller.NewUnmarshaler(data[prefixLen:]))
+	if err != nil || !s.upcast {
+		return v, err
+	}
+
+	return s.registry.upcast(subject, v)
 }
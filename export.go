@@ -0,0 +1,95 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/tryfix/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportFormat is the output format of Registry.Export
+type ExportFormat string
+
+const (
+	ExportTable ExportFormat = `table`
+	ExportJSON  ExportFormat = `json`
+	ExportYAML  ExportFormat = `yaml`
+)
+
+type exportRecord struct {
+	Subject      string    `json:"subject" yaml:"subject"`
+	Version      int       `json:"version" yaml:"version"`
+	ID           int       `json:"id" yaml:"id"`
+	SchemaType   string    `json:"schemaType" yaml:"schemaType"`
+	Fingerprint  string    `json:"fingerprint" yaml:"fingerprint"`
+	Unmarshaler  bool      `json:"unmarshaler" yaml:"unmarshaler"`
+	RegisteredAt time.Time `json:"registeredAt" yaml:"registeredAt"`
+}
+
+// Export writes the registered subjects sorted by subject and version to w in the given format. It is safe to call
+// while the background sync is running
+func (r *Registry) Export(w io.Writer, format ExportFormat) error {
+	var records []exportRecord
+	for _, subject := range r.Subjects() {
+		for _, version := range subject.Versions {
+			records = append(records, newExportRecord(version))
+		}
+	}
+
+	switch format {
+	case ExportTable:
+		renderTable(w, records)
+		return nil
+	case ExportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent(``, `  `)
+		if err := encoder.Encode(records); err != nil {
+			return errors.WithPrevious(err, `json export failed`)
+		}
+		return nil
+	case ExportYAML:
+		encoder := yaml.NewEncoder(w)
+		if err := encoder.Encode(records); err != nil {
+			return errors.WithPrevious(err, `yaml export failed`)
+		}
+		return encoder.Close()
+	}
+
+	return errors.New(fmt.Sprintf(`unsupported export format [%s]`, format))
+}
+
+func newExportRecord(snapshot SchemaSnapshot) exportRecord {
+	return exportRecord{
+		Subject:      snapshot.Subject,
+		Version:      int(snapshot.Version),
+		ID:           snapshot.ID,
+		SchemaType:   string(snapshot.SchemaType),
+		Fingerprint:  snapshot.Fingerprint,
+		Unmarshaler:  snapshot.HasUnmarshaler,
+		RegisteredAt: snapshot.RegisteredAt,
+	}
+}
+
+func renderTable(w io.Writer, records []exportRecord) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{`Schema Id`, `subject`, `version`, `type`, `fingerprint`, `decoderFunc`})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT})
+	table.SetAutoFormatHeaders(true)
+
+	for _, record := range records {
+		table.Append([]string{
+			fmt.Sprint(record.ID),
+			record.Subject,
+			fmt.Sprint(record.Version),
+			record.SchemaType,
+			record.Fingerprint,
+			fmt.Sprint(record.Unmarshaler),
+		})
+	}
+
+	table.Render()
+}
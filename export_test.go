@@ -0,0 +1,117 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	registry "github.com/riferrei/srclient"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRegistry_ExportJSON(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if _, err := reg.client.SetSchema(200, `a_subject`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, VersionAll, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`a_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	b := new(bytes.Buffer)
+	if err := reg.Export(b, ExportJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []exportRecord
+	if err := json.Unmarshal(b.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf(`need 3 records, have %d`, len(records))
+	}
+
+	order := []struct {
+		subject string
+		version int
+	}{{`a_subject`, 1}, {`test_subject`, 1}, {`test_subject`, 2}}
+	for i, o := range order {
+		if records[i].Subject != o.subject || records[i].Version != o.version {
+			t.Errorf(`need %s:%d at %d, have %s:%d`, o.subject, o.version, i, records[i].Subject, records[i].Version)
+		}
+	}
+
+	// both subjects share the same schema
+	if records[0].Fingerprint == `` || records[0].Fingerprint != records[1].Fingerprint {
+		t.Errorf(`need matching fingerprints, have %s and %s`, records[0].Fingerprint, records[1].Fingerprint)
+	}
+
+	if !records[0].Unmarshaler || records[0].SchemaType != `AVRO` {
+		t.Errorf(`unexpected record %+v`, records[0])
+	}
+}
+
+func TestRegistry_ExportYAML(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if _, err := reg.client.SetSchema(200, `a_subject`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, VersionAll, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`a_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	b := new(bytes.Buffer)
+	if err := reg.Export(b, ExportYAML); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []exportRecord
+	if err := yaml.Unmarshal(b.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 3 || records[2].ID != 101 {
+		t.Errorf(`unexpected records %+v`, records)
+	}
+}
+
+func TestRegistry_ExportTable(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if _, err := reg.client.SetSchema(200, `a_subject`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, VersionAll, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`a_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	b := new(bytes.Buffer)
+	if err := reg.Export(b, ExportTable); err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+	if strings.Index(out, `a_subject`) > strings.Index(out, `test_subject`) {
+		t.Errorf(`need rows sorted by subject, have %s`, out)
+	}
+
+	if err := reg.Export(b, `xml`); err == nil {
+		t.Error(`expected error for unsupported format`)
+	}
+}
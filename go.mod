@@ -11,6 +11,7 @@ require (
 	github.com/tryfix/errors v1.0.0
 	github.com/tryfix/log v1.4.0
 	google.golang.org/protobuf v1.35.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,75 @@
+package schemaregistry
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthStatus reports the state of the Registry, it can be used for liveness and readiness probes
+type HealthStatus struct {
+	LastSyncAt          time.Time // Last time the background sync completed without errors
+	LastSyncError       error     // Error of the last failed background sync, kept after later successful syncs
+	LastSyncErrorAt     time.Time
+	ConsecutiveFailures int // Background sync runs failed since the last successful one
+	CachedSubjects      int
+	CachedIDs           int
+	PendingLookups      int  // Unknown schema ID lookups waiting on the registry
+	Reachable           bool // Whether the last registry call got a response, false until the first call
+}
+
+type healthState struct {
+	lastSyncAt          time.Time
+	lastSyncError       error
+	lastSyncErrorAt     time.Time
+	consecutiveFailures int
+	pendingLookups      int64
+	mu                  sync.Mutex
+}
+
+// Health returns the current HealthStatus of the Registry
+func (r *Registry) Health() HealthStatus {
+	r.health.mu.Lock()
+	status := HealthStatus{
+		LastSyncAt:          r.health.lastSyncAt,
+		LastSyncError:       r.health.lastSyncError,
+		LastSyncErrorAt:     r.health.lastSyncErrorAt,
+		ConsecutiveFailures: r.health.consecutiveFailures,
+		PendingLookups:      int(atomic.LoadInt64(&r.health.pendingLookups)),
+	}
+	r.health.mu.Unlock()
+
+	if client, ok := r.client.(*registryClient); ok {
+		status.Reachable = client.reachable.Load()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	status.CachedSubjects = len(r.subjects)
+	status.CachedIDs = len(r.idMap)
+
+	return status
+}
+
+func (r *Registry) recordSync(err error) {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+
+	if err != nil {
+		r.health.lastSyncError = err
+		r.health.lastSyncErrorAt = time.Now()
+		r.health.consecutiveFailures++
+		return
+	}
+
+	r.health.lastSyncAt = time.Now()
+	r.health.consecutiveFailures = 0
+}
+
+// trackLookup counts an unknown schema ID lookup as pending until the returned func is called
+func (r *Registry) trackLookup() (done func()) {
+	atomic.AddInt64(&r.health.pendingLookups, 1)
+	return func() {
+		atomic.AddInt64(&r.health.pendingLookups, -1)
+	}
+}
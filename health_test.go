@@ -0,0 +1,82 @@
+package schemaregistry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tryfix/log"
+)
+
+func TestRegistry_Health(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if reg.Health().Reachable {
+		t.Error(`need registry to be reported unreachable before the first call`)
+	}
+
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	health := reg.Health()
+	if !health.Reachable || health.LastSyncAt.IsZero() || health.LastSyncError != nil {
+		t.Errorf(`unexpected health %+v`, health)
+	}
+
+	// The sync added version 2 of the registered subject
+	if health.CachedSubjects != 1 || health.CachedIDs != 2 || health.PendingLookups != 0 {
+		t.Errorf(`unexpected health %+v`, health)
+	}
+}
+
+func TestRegistry_HealthSyncFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	reg, err := NewRegistry(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sync := &backgroundSync{registry: reg, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+	sync.checkRegistryAndAdd()
+
+	health := reg.Health()
+	if health.Reachable || health.ConsecutiveFailures != 2 || health.LastSyncError == nil {
+		t.Errorf(`unexpected health %+v`, health)
+	}
+
+	if !health.LastSyncAt.IsZero() {
+		t.Errorf(`need no successful syncs, have %s`, health.LastSyncAt)
+	}
+}
+
+func TestRegistry_HealthSchemaContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != `/contexts/.tenant/subjects` {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	reg, err := NewRegistry(srv.URL, WithSchemaContext(`tenant`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sync := &backgroundSync{registry: reg, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	if health := reg.Health(); !health.Reachable || health.LastSyncError != nil {
+		t.Errorf(`need calls of the schema context client to be reported, have %+v`, health)
+	}
+}
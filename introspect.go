@@ -0,0 +1,134 @@
+package schemaregistry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	registry "github.com/riferrei/srclient"
+)
+
+// ParsedSchemaProvider is implemented by Marshallers which expose their parsed schema (ex: avro.Schema)
+type ParsedSchemaProvider interface {
+	ParsedSchema() interface{}
+}
+
+// SchemaSnapshot is a copy of a schema version held by the Registry. Changes to the Registry after the snapshot
+// was taken are not reflected in it
+type SchemaSnapshot struct {
+	Subject        string // Qualified subject name (ex: :.tenant:subject) for subjects outside the default context
+	Context        string // Schema context, empty for the default context
+	Version        Version
+	ID             int
+	SchemaType     registry.SchemaType
+	Schema         string
+	ParsedSchema   interface{} // avro.Schema for avro subjects, nil when the Marshaller does not expose it
+	References     []registry.Reference
+	Fingerprint    string // Hex encoded SHA256 of the canonical avro schema, or of the schema text for other types
+	HasUnmarshaler bool
+	Deleted        DeletedState // Whether the background sync found the version deleted in the registry
+	RegisteredAt   time.Time    // Time the version was registered or discovered by the background sync
+}
+
+// SubjectSnapshot holds the snapshots of all versions of a subject sorted by version
+type SubjectSnapshot struct {
+	Subject  string
+	Versions []SchemaSnapshot
+}
+
+// Subjects returns snapshots of all registered subjects sorted by subject name
+func (r *Registry) Subjects() []SubjectSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subjects := make([]SubjectSnapshot, 0, len(r.subjects))
+	for name := range r.subjects {
+		subjects = append(subjects, SubjectSnapshot{
+			Subject:  name,
+			Versions: r.versionSnapshots(name),
+		})
+	}
+
+	sort.Slice(subjects, func(i, j int) bool {
+		return subjects[i].Subject < subjects[j].Subject
+	})
+
+	return subjects
+}
+
+// Versions returns snapshots of the registered versions of the subject sorted by version. Nil is returned when the
+// subject is not registered
+func (r *Registry) Versions(subject string) []SchemaSnapshot {
+	subject = r.qualify(subject)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.versionSnapshots(subject)
+}
+
+// SubjectByID returns the snapshot of the schema cached under the schema ID of the Registry's schema context
+func (r *Registry) SubjectByID(schemaID int) (SchemaSnapshot, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subject, ok := r.idMap[schemaKey{context: r.options.schemaContext, id: schemaID}]
+	if !ok {
+		return SchemaSnapshot{}, false
+	}
+
+	return subject.snapshot(), true
+}
+
+// versionSnapshots must be called while holding the read lock
+func (r *Registry) versionSnapshots(subject string) []SchemaSnapshot {
+	versions, ok := r.subjects[subject]
+	if !ok {
+		return nil
+	}
+
+	snapshots := make([]SchemaSnapshot, 0, len(versions))
+	for _, s := range versions {
+		snapshots = append(snapshots, s.snapshot())
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Version < snapshots[j].Version
+	})
+
+	return snapshots
+}
+
+func (s *Subject) snapshot() SchemaSnapshot {
+	snapshot := SchemaSnapshot{
+		Subject:        s.Subject,
+		Context:        s.context,
+		Version:        s.Version,
+		ID:             s.Id,
+		SchemaType:     s.SchemaType,
+		Schema:         s.Schema,
+		HasUnmarshaler: s.UnmarshalerFunc != nil,
+		Deleted:        s.DeletedState(),
+		RegisteredAt:   s.registeredAt,
+	}
+
+	if len(s.References) > 0 {
+		snapshot.References = append([]registry.Reference(nil), s.References...)
+	}
+
+	if provider, ok := s.marsheller.(ParsedSchemaProvider); ok {
+		snapshot.ParsedSchema = provider.ParsedSchema()
+	}
+
+	if avroSchema, ok := snapshot.ParsedSchema.(avro.Schema); ok {
+		fingerprint := avroSchema.Fingerprint()
+		snapshot.Fingerprint = hex.EncodeToString(fingerprint[:])
+	} else {
+		fingerprint := sha256.Sum256([]byte(s.Schema))
+		snapshot.Fingerprint = hex.EncodeToString(fingerprint[:])
+	}
+
+	return snapshot
+}
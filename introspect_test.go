@@ -0,0 +1,67 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	registry "github.com/riferrei/srclient"
+)
+
+func TestRegistry_Subjects(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if _, err := reg.client.SetSchema(200, `a_subject`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, VersionAll, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`a_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	subjects := reg.Subjects()
+	if len(subjects) != 2 || subjects[0].Subject != `a_subject` || subjects[1].Subject != `test_subject` {
+		t.Fatalf(`need subjects sorted by name, have %+v`, subjects)
+	}
+
+	versions := reg.Versions(`test_subject`)
+	if len(versions) != 2 || versions[0].Version != 1 || versions[1].Version != 2 {
+		t.Fatalf(`need versions 1 and 2, have %+v`, versions)
+	}
+
+	v2 := versions[1]
+	if v2.ID != 101 || v2.SchemaType != registry.Avro || v2.Schema == `` || v2.RegisteredAt.IsZero() {
+		t.Errorf(`unexpected snapshot %+v`, v2)
+	}
+
+	if _, ok := v2.ParsedSchema.(*avro.RecordSchema); !ok {
+		t.Errorf(`need parsed avro record schema, have %T`, v2.ParsedSchema)
+	}
+
+	if reg.Versions(`unknown`) != nil {
+		t.Error(`need nil versions for unregistered subjects`)
+	}
+}
+
+func TestRegistry_SubjectByID(t *testing.T) {
+	reg := setupSampleRegistry(t)
+
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, ok := reg.SubjectByID(100)
+	if !ok {
+		t.Fatal(`need schema id 100 to be cached`)
+	}
+
+	if snapshot.Subject != `test_subject` || snapshot.Version != 1 {
+		t.Errorf(`unexpected snapshot %+v`, snapshot)
+	}
+
+	if _, ok := reg.SubjectByID(101); ok {
+		t.Error(`need schema id 101 to be missing`)
+	}
+}
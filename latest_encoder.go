@@ -0,0 +1,180 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+	"github.com/tryfix/errors"
+)
+
+// LatestPolicy decides which versions a LatestEncoder moves to when the background sync finds them
+type LatestPolicy int
+
+const (
+	// FollowAll encodes with the newest cached version
+	FollowAll LatestPolicy = iota
+	// FollowCompatible moves to the newest cached version only when consumers of the current version can read it
+	FollowCompatible
+	// Pinned keeps encoding with the newest version seen when the encoder was created
+	Pinned
+)
+
+// String returns the policy name
+func (p LatestPolicy) String() string {
+	switch p {
+	case FollowCompatible:
+		return `FollowCompatible`
+	case Pinned:
+		return `Pinned`
+	}
+
+	return `FollowAll`
+}
+
+// LatestEncoder encodes messages with the newest version of a subject allowed by its LatestPolicy. Unlike
+// WithLatestSchema the version is resolved on every Encode so versions found by the background sync are picked up
+type LatestEncoder struct {
+	registry *Registry
+	subject  string
+	policy   LatestPolicy
+	current  *Subject
+	checked  map[Version]bool // Compatibility results of versions newer than current
+	checking Version          // Version being checked, Encode keeps using current meanwhile
+	mu       sync.Mutex
+}
+
+// LatestEncoder returns a LatestEncoder for the registered subject
+func (r *Registry) LatestEncoder(subject string, policy LatestPolicy) (*LatestEncoder, error) {
+	subject = r.qualify(subject)
+
+	latest, ok := r.latestSubject(subject)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(`unregistred subject [%s]`, subject))
+	}
+
+	return &LatestEncoder{
+		registry: r,
+		subject:  subject,
+		policy:   policy,
+		current:  latest,
+		checked:  map[Version]bool{},
+	}, nil
+}
+
+// Version returns the version the next message will be encoded with
+func (e *LatestEncoder) Version() Version {
+	return e.resolve().Version
+}
+
+// Encode encodes the message with the version allowed by the policy
+func (e *LatestEncoder) Encode(v interface{}) ([]byte, error) {
+	return NewRegistryEncoder(e.registry, e.resolve()).Encode(v)
+}
+
+// EncodeAppend appends the message encoded with the version allowed by the policy to dst
+func (e *LatestEncoder) EncodeAppend(dst []byte, v interface{}) ([]byte, error) {
+	return NewRegistryEncoder(e.registry, e.resolve()).(AppendEncoder).EncodeAppend(dst, v)
+}
+
+// Decode decodes the message using the schema ID it was encoded with
+func (e *LatestEncoder) Decode(data []byte) (interface{}, error) {
+	return NewRegistryEncoder(e.registry, e.resolve()).Decode(data)
+}
+
+func (e *LatestEncoder) resolve() *Subject {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.policy == Pinned {
+		return e.current
+	}
+
+	latest, ok := e.registry.latestSubject(e.subject)
+	if !ok || latest.Version <= e.current.Version {
+		return e.current
+	}
+
+	if e.policy == FollowAll {
+		e.current = latest
+		return e.current
+	}
+
+	compatible, checked := e.checked[latest.Version]
+	if !checked {
+		if e.checking == latest.Version {
+			return e.current
+		}
+
+		// The check can call the registry, concurrent calls keep encoding with current instead of waiting for it
+		current := e.current
+		e.checking = latest.Version
+		err := func() error {
+			e.mu.Unlock()
+			defer e.mu.Lock()
+			return e.registry.checkCompatible(current, latest)
+		}()
+		e.checking = 0
+
+		if err != nil {
+			e.registry.logger.Warn(fmt.Sprintf(`Subject %s is not compatible with %s, keeping %s due to %s`,
+				latest, current, current, err))
+		}
+
+		if e.current != current {
+			return e.current
+		}
+
+		compatible = err == nil
+		e.checked[latest.Version] = compatible
+	}
+
+	if compatible {
+		e.current = latest
+		e.checked = map[Version]bool{}
+	}
+
+	return e.current
+}
+
+// latestSubject returns the cached version of the subject with the highest version number
+func (r *Registry) latestSubject(subject string) (*Subject, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *Subject
+	for _, s := range r.subjects[subject] {
+		if latest == nil || s.Version > latest.Version {
+			latest = s
+		}
+	}
+
+	return latest, latest != nil
+}
+
+// checkCompatible checks whether consumers of the current version can read messages written with the candidate.
+// Avro schemas are checked locally, other schema types are checked by the registry
+func (r *Registry) checkCompatible(current, candidate *Subject) error {
+	reader, readerOk := current.marsheller.(ParsedSchemaProvider)
+	writer, writerOk := candidate.marsheller.(ParsedSchemaProvider)
+	if readerOk && writerOk {
+		readerSchema, readerOk := reader.ParsedSchema().(avro.Schema)
+		writerSchema, writerOk := writer.ParsedSchema().(avro.Schema)
+		if readerOk && writerOk {
+			return avro.NewSchemaCompatibility().Compatible(readerSchema, writerSchema)
+		}
+	}
+
+	compatible, err := r.client.IsSchemaCompatible(candidate.Subject, candidate.Schema,
+		strconv.Itoa(int(current.Version)), candidate.SchemaType, candidate.References...)
+	if err != nil {
+		return errors.WithPrevious(err, fmt.Sprintf(`compatibility check of %s failed`, candidate))
+	}
+
+	if !compatible {
+		return errors.New(fmt.Sprintf(`registry reported %s incompatible with version %s`, candidate, current.Version))
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	registry "github.com/riferrei/srclient"
+	"github.com/tryfix/log"
+)
+
+const incompatibleSchema = `{
+	"type": "record",
+	"name": "SampleRecord",
+	"namespace": "com.mycorp.mynamespace",
+	"fields": [
+		{"name": "field1", "type": "string"},
+		{"name": "field2", "type": "double"},
+		{"name": "field3", "type": "string"},
+		{"name": "field4", "type": "string", "default": ""}
+	]
+}`
+
+func TestRegistry_LatestEncoder(t *testing.T) {
+	reg := setupSampleRegistry(t)
+
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	encoders := map[LatestPolicy]*LatestEncoder{}
+	for _, policy := range []LatestPolicy{FollowAll, FollowCompatible, Pinned} {
+		encoder, err := reg.LatestEncoder(`test_subject`, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoders[policy] = encoder
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	assertVersions := func(versions map[LatestPolicy]Version) {
+		t.Helper()
+		for policy, version := range versions {
+			byt, err := encoders[policy].Encode(SampleV2{Field3: `latest`})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			snapshot, _ := reg.SubjectByID(int(binary.BigEndian.Uint32(byt[1:prefixLen])))
+			if snapshot.Version != version {
+				t.Errorf(`need %s to encode with version %d, have %d`, policy, version, snapshot.Version)
+			}
+		}
+	}
+
+	sync.checkRegistryAndAdd()
+	assertVersions(map[LatestPolicy]Version{FollowAll: 2, FollowCompatible: 2, Pinned: 1})
+
+	if _, err := reg.client.SetSchema(102, `test_subject`, incompatibleSchema, registry.Avro, 3); err != nil {
+		t.Fatal(err)
+	}
+	sync.checkRegistryAndAdd()
+
+	if v := encoders[FollowAll].Version(); v != 3 {
+		t.Errorf(`need FollowAll to move to version 3, have %d`, v)
+	}
+
+	if v := encoders[FollowCompatible].Version(); v != 2 {
+		t.Errorf(`need FollowCompatible to stay on version 2, have %d`, v)
+	}
+
+	if _, err := reg.LatestEncoder(`unknown_subject`, FollowAll); err == nil {
+		t.Error(`need an error for an unregistered subject`)
+	}
+}
+
+// blockingCompatibilityClient holds IsSchemaCompatible calls until release is closed
+type blockingCompatibilityClient struct {
+	*registry.MockSchemaRegistryClient
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingCompatibilityClient) IsSchemaCompatible(string, string, string, registry.SchemaType,
+	...registry.Reference) (bool, error) {
+	close(c.started)
+	<-c.release
+
+	return true, nil
+}
+
+func TestLatestEncoder_CompatibilityCheckUnlocked(t *testing.T) {
+	reg := setupMockRegistry(1)
+	client := &blockingCompatibilityClient{
+		MockSchemaRegistryClient: reg.client,
+		started:                  make(chan struct{}),
+		release:                  make(chan struct{}),
+	}
+	reg.Registry.client.(*registryClient).endpoints[0].client = client
+
+	// Protobuf subjects are added directly since the mock client cannot parse proto schemas
+	reg.subjects[`test_subject_proto`] = map[Version]*Subject{}
+	for version := Version(1); version <= 2; version++ {
+		reg.subjects[`test_subject_proto`][version] = &Subject{
+			Schema:     testSchemas[`proto`],
+			Subject:    `test_subject_proto`,
+			Version:    version,
+			Id:         199 + int(version),
+			SchemaType: registry.Protobuf,
+			marsheller: NewProtoMarshaller(),
+		}
+	}
+
+	encoder := &LatestEncoder{
+		registry: reg.Registry,
+		subject:  `test_subject_proto`,
+		policy:   FollowCompatible,
+		current:  reg.subjects[`test_subject_proto`][1],
+		checked:  map[Version]bool{},
+	}
+
+	done := make(chan Version)
+	go func() {
+		done <- encoder.Version()
+	}()
+	<-client.started
+
+	resolved := make(chan Version)
+	go func() {
+		resolved <- encoder.Version()
+	}()
+
+	select {
+	case v := <-resolved:
+		if v != 1 {
+			t.Errorf(`need version 1 while version 2 is checked, have %d`, v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal(`need the encoder not to wait for the compatibility check`)
+	}
+
+	close(client.release)
+	if v := <-done; v != 2 {
+		t.Errorf(`need version 2 after the check, have %d`, v)
+	}
+}
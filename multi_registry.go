@@ -0,0 +1,134 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tryfix/errors"
+)
+
+// MultiRegistry holds named Registry instances (ex: one per Kafka cluster) whose schema IDs may overlap. Each
+// Registry keeps its own caches, decoding is routed by the cluster name or by topics routed to a cluster
+type MultiRegistry struct {
+	registries map[string]*Registry
+	topics     map[string]string
+	mu         sync.RWMutex
+}
+
+// NewMultiRegistry returns an empty MultiRegistry
+func NewMultiRegistry() *MultiRegistry {
+	return &MultiRegistry{
+		registries: map[string]*Registry{},
+		topics:     map[string]string{},
+	}
+}
+
+// Add adds the Registry under the cluster name
+func (m *MultiRegistry) Add(cluster string, registry *Registry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.registries[cluster]; ok {
+		return errors.New(fmt.Sprintf(`registry for cluster [%s] already exists`, cluster))
+	}
+
+	m.registries[cluster] = registry
+
+	return nil
+}
+
+// Route routes the messages of the topics to the cluster's Registry
+func (m *MultiRegistry) Route(cluster string, topics ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.registries[cluster]; !ok {
+		return errors.New(fmt.Sprintf(`unknown cluster [%s]`, cluster))
+	}
+
+	for _, topic := range topics {
+		m.topics[topic] = cluster
+	}
+
+	return nil
+}
+
+// Registry returns the Registry of the cluster
+func (m *MultiRegistry) Registry(cluster string) (*Registry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	registry, ok := m.registries[cluster]
+
+	return registry, ok
+}
+
+// Clusters returns the sorted cluster names
+func (m *MultiRegistry) Clusters() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clusters := make([]string, 0, len(m.registries))
+	for cluster := range m.registries {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	return clusters
+}
+
+// ForCluster returns the GenericEncoder of the cluster's Registry
+func (m *MultiRegistry) ForCluster(cluster string) (Encoder, error) {
+	registry, ok := m.Registry(cluster)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(`unknown cluster [%s]`, cluster))
+	}
+
+	return registry.GenericEncoder(), nil
+}
+
+// ForTopic returns the GenericEncoder of the Registry the topic is routed to
+func (m *MultiRegistry) ForTopic(topic string) (Encoder, error) {
+	m.mu.RLock()
+	cluster, ok := m.topics[topic]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(`topic [%s] is not routed to a cluster`, topic))
+	}
+
+	return m.ForCluster(cluster)
+}
+
+// Decode decodes the message using the cluster's Registry
+func (m *MultiRegistry) Decode(cluster string, data []byte) (interface{}, error) {
+	encoder, err := m.ForCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.Decode(data)
+}
+
+// DecodeTopic decodes the message using the Registry the topic is routed to
+func (m *MultiRegistry) DecodeTopic(topic string, data []byte) (interface{}, error) {
+	encoder, err := m.ForTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.Decode(data)
+}
+
+// Sync starts the background sync of every Registry which has it enabled
+func (m *MultiRegistry) Sync() error {
+	for _, cluster := range m.Clusters() {
+		registry, _ := m.Registry(cluster)
+		if err := registry.Sync(); err != nil {
+			return errors.WithPrevious(err, fmt.Sprintf(`starting sync for cluster [%s] failed`, cluster))
+		}
+	}
+
+	return nil
+}
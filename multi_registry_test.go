@@ -0,0 +1,71 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	registry "github.com/riferrei/srclient"
+)
+
+func TestMultiRegistry_Decode(t *testing.T) {
+	// Both registries use the schema ID 100 for different schemas
+	primary := setupSampleRegistry(t)
+	if err := primary.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	secondary := setupMockRegistry(1)
+	if _, err := secondary.client.SetSchema(100, `test_subject`, testSchemas[`avro_v2`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := secondary.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV2]()); err != nil {
+		t.Fatal(err)
+	}
+
+	multi := NewMultiRegistry()
+	if err := multi.Add(`primary`, primary.Registry); err != nil {
+		t.Fatal(err)
+	}
+	if err := multi.Add(`secondary`, secondary.Registry); err != nil {
+		t.Fatal(err)
+	}
+	if err := multi.Add(`primary`, secondary.Registry); err == nil {
+		t.Error(`need an error for a duplicate cluster`)
+	}
+
+	if err := multi.Route(`secondary`, `events`); err != nil {
+		t.Fatal(err)
+	}
+	if err := multi.Route(`unknown`, `events`); err == nil {
+		t.Error(`need an error for an unknown cluster`)
+	}
+
+	byt, err := primary.WithSchema(`test_subject`, 1).Encode(SampleV1{Field1: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := multi.Decode(`primary`, byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v.(SampleV1); !ok {
+		t.Errorf(`need SampleV1, have %T`, v)
+	}
+
+	byt, err = secondary.WithSchema(`test_subject`, 1).Encode(SampleV2{Field1: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err = multi.DecodeTopic(`events`, byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v.(SampleV2); !ok {
+		t.Errorf(`need SampleV2, have %T`, v)
+	}
+
+	if _, err := multi.DecodeTopic(`other`, byt); err == nil {
+		t.Error(`need an error for an unrouted topic`)
+	}
+}
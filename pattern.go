@@ -0,0 +1,125 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tryfix/errors"
+)
+
+// SubjectPattern matches subject names for RegisterPattern
+type SubjectPattern interface {
+	Match(subject string) bool
+	String() string
+}
+
+type prefixPattern string
+
+func (p prefixPattern) Match(subject string) bool {
+	return strings.HasPrefix(subject, string(p))
+}
+
+func (p prefixPattern) String() string {
+	return string(p) + `*`
+}
+
+type regexpPattern struct {
+	*regexp.Regexp
+}
+
+func (p regexpPattern) Match(subject string) bool {
+	return p.MatchString(subject)
+}
+
+// SubjectPrefix returns a SubjectPattern matching subjects starting with prefix
+func SubjectPrefix(prefix string) SubjectPattern {
+	return prefixPattern(prefix)
+}
+
+// SubjectRegexp returns a SubjectPattern matching subjects against re
+func SubjectRegexp(re *regexp.Regexp) SubjectPattern {
+	return regexpPattern{re}
+}
+
+// PatternError holds the errors of the subjects matching a pattern which could not be registered
+type PatternError struct {
+	Pattern string
+	Errors  []error
+}
+
+func (e *PatternError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("registering subjects matching %s failed:\n%s", e.Pattern, strings.Join(msgs, "\n"))
+}
+
+func (e *PatternError) Unwrap() []error {
+	return e.Errors
+}
+
+type registeredPattern struct {
+	pattern         SubjectPattern
+	unmarshalerFunc UnmarshalerFunc
+	options         []RegisterOption
+}
+
+// RegisterPattern registers all the versions of every subject matching the pattern. With background sync enabled
+// subjects created later which match the pattern are registered as they appear. Patterns are matched against the
+// subject name without its schema context. Every matching subject is registered even when others fail, the failures
+// are returned together as a *PatternError
+func (r *Registry) RegisterPattern(pattern SubjectPattern, unmarshalerFunc UnmarshalerFunc,
+	options ...RegisterOption) error {
+	r.mu.Lock()
+	r.patterns = append(r.patterns, registeredPattern{
+		pattern:         pattern,
+		unmarshalerFunc: unmarshalerFunc,
+		options:         options,
+	})
+	r.mu.Unlock()
+
+	subjects, err := r.listSubjects(priorityDefault)
+	if err != nil {
+		return errors.WithPrevious(err, fmt.Sprintf(`Fetching subjects for pattern %s failed.`, pattern))
+	}
+
+	var errs []error
+	for _, subject := range subjects {
+		if _, name := SplitSubject(subject); !pattern.Match(name) || r.subjectRegistered(subject) {
+			continue
+		}
+
+		if err := r.Register(subject, VersionAll, unmarshalerFunc, options...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &PatternError{Pattern: pattern.String(), Errors: errs}
+	}
+
+	return nil
+}
+
+// matchPattern returns the first registered pattern matching the name of the qualified subject
+func (r *Registry) matchPattern(subject string) (registeredPattern, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.patternFor(subject)
+}
+
+// patternFor must be called while holding the lock
+func (r *Registry) patternFor(subject string) (registeredPattern, bool) {
+	_, name := SplitSubject(subject)
+	for _, p := range r.patterns {
+		if p.pattern.Match(name) {
+			return p, true
+		}
+	}
+
+	return registeredPattern{}, false
+}
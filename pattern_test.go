@@ -0,0 +1,107 @@
+package schemaregistry
+
+import (
+	stderrors "errors"
+	"regexp"
+	"testing"
+
+	registry "github.com/riferrei/srclient"
+	"github.com/tryfix/log"
+)
+
+func TestRegistry_RegisterPattern(t *testing.T) {
+	reg := setupMockRegistry(1)
+	for id, subject := range map[int]string{100: `tenant-a-events`, 101: `other-events`} {
+		if _, err := reg.client.SetSchema(id, subject, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := reg.RegisterPattern(SubjectPrefix(`tenant-`), NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if reg.Versions(`tenant-a-events`) == nil {
+		t.Error(`need tenant-a-events to be registered`)
+	}
+
+	if reg.Versions(`other-events`) != nil {
+		t.Error(`need other-events not to be registered`)
+	}
+
+	if _, err := reg.client.SetSchema(102, `tenant-b-events`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	if reg.Versions(`tenant-b-events`) == nil {
+		t.Error(`need tenant-b-events to be discovered by the background sync`)
+	}
+
+	if reg.Versions(`other-events`) != nil {
+		t.Error(`need other-events not to be registered`)
+	}
+}
+
+func TestRegistry_RegisterPatternErrors(t *testing.T) {
+	reg := setupMockRegistry(1)
+	for id, subject := range map[int]string{100: `tenant-a-events`, 101: `tenant-b-events`} {
+		if _, err := reg.client.SetSchema(id, subject, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reg.Registry.client.(*registryClient).endpoints[0].client = &deniedClient{
+		MockSchemaRegistryClient: reg.client,
+		subject:                  `tenant-a-events`,
+	}
+
+	var patternErr *PatternError
+	err := reg.RegisterPattern(SubjectPrefix(`tenant-`), NewUnmarshalerFunc[SampleV1]())
+	if !stderrors.As(err, &patternErr) || len(patternErr.Errors) != 1 {
+		t.Fatalf(`need a PatternError for tenant-a-events, have %v`, err)
+	}
+
+	if reg.Versions(`tenant-b-events`) == nil {
+		t.Error(`need tenant-b-events to be registered after tenant-a-events failed`)
+	}
+}
+
+func TestSubjectPattern_Match(t *testing.T) {
+	pattern := SubjectRegexp(regexp.MustCompile(`^tenant-[a-z]+-events$`))
+	if !pattern.Match(`tenant-a-events`) || pattern.Match(`tenant-1-events`) {
+		t.Error(`unexpected regexp pattern match`)
+	}
+
+	if !SubjectPrefix(`tenant-`).Match(`tenant-1`) || SubjectPrefix(`tenant-`).Match(`other`) {
+		t.Error(`unexpected prefix pattern match`)
+	}
+}
+
+func TestRegistry_RegisterPatternSchemaContext(t *testing.T) {
+	reg := setupMockRegistry(1, WithSchemaContext(`.tenant`))
+	if _, err := reg.client.SetSchema(100, `:.tenant:tenant-a-events`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.RegisterPattern(SubjectPrefix(`tenant-`), NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if reg.Versions(`tenant-a-events`) == nil {
+		t.Error(`need tenant-a-events of the schema context to be registered`)
+	}
+
+	if _, err := reg.client.SetSchema(101, `:.tenant:tenant-b-events`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	if reg.Versions(`tenant-b-events`) == nil {
+		t.Error(`need tenant-b-events to be discovered by the background sync`)
+	}
+}
@@ -0,0 +1,167 @@
+package schemaregistry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tryfix/errors"
+)
+
+// Confluent serializer property names understood by NewRegistryFromProperties and NewSerializer
+const (
+	PropSchemaRegistryURL           = `schema.registry.url`
+	PropBasicAuthCredentialsSource  = `basic.auth.credentials.source`
+	PropBasicAuthUserInfo           = `basic.auth.user.info`
+	PropBearerAuthCredentialsSource = `bearer.auth.credentials.source`
+	PropBearerAuthToken             = `bearer.auth.token`
+	PropSSLTruststoreLocation       = `schema.registry.ssl.truststore.location`
+	PropSSLTruststoreType           = `schema.registry.ssl.truststore.type`
+	PropSSLKeystoreLocation         = `schema.registry.ssl.keystore.location`
+	PropSSLKeystoreType             = `schema.registry.ssl.keystore.type`
+	PropAutoRegisterSchemas         = `auto.register.schemas`
+	PropUseLatestVersion            = `use.latest.version`
+	PropLatestCompatibilityStrict   = `latest.compatibility.strict`
+	PropKeySubjectNameStrategy      = `key.subject.name.strategy`
+	PropValueSubjectNameStrategy    = `value.subject.name.strategy`
+)
+
+// NewRegistryFromProperties returns a Registry configured with Confluent client properties. Supported are the
+// registry URLs, USER_INFO and URL basic auth, STATIC_TOKEN bearer auth and PEM trust and key stores. Options are
+// applied after the ones derived from the properties
+func NewRegistryFromProperties(props map[string]string, opts ...Option) (*Registry, error) {
+	urls, propOpts, err := propertyOptions(props)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegistry(urls, append(propOpts, opts...)...)
+}
+
+func propertyOptions(props map[string]string) (string, []Option, error) {
+	urls := props[PropSchemaRegistryURL]
+	if strings.TrimSpace(urls) == `` {
+		return ``, nil, errors.New(fmt.Sprintf(`property %s is required`, PropSchemaRegistryURL))
+	}
+
+	var opts []Option
+	switch source := strings.ToUpper(props[PropBasicAuthCredentialsSource]); source {
+	case ``, `USER_INFO`:
+		if info, ok := props[PropBasicAuthUserInfo]; ok {
+			username, password, _ := strings.Cut(info, `:`)
+			opts = append(opts, WithBasicAuth(username, password))
+		}
+	case `URL`:
+		stripped, username, password, err := splitURLUserInfo(urls)
+		if err != nil {
+			return ``, nil, err
+		}
+
+		urls = stripped
+		opts = append(opts, WithBasicAuth(username, password))
+	default:
+		return ``, nil, errors.New(fmt.Sprintf(`unsupported %s [%s]`, PropBasicAuthCredentialsSource, source))
+	}
+
+	if source, ok := props[PropBearerAuthCredentialsSource]; ok {
+		if !strings.EqualFold(source, `STATIC_TOKEN`) {
+			return ``, nil, errors.New(fmt.Sprintf(`unsupported %s [%s]`, PropBearerAuthCredentialsSource, source))
+		}
+
+		opts = append(opts, WithBearerTokenSource(StaticTokenSource(props[PropBearerAuthToken])))
+	}
+
+	tlsConfig, err := propertyTLSConfig(props)
+	if err != nil {
+		return ``, nil, err
+	}
+
+	if tlsConfig != nil {
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+
+	return urls, opts, nil
+}
+
+// splitURLUserInfo removes the user info from the comma separated URLs and returns the first one found
+func splitURLUserInfo(urls string) (stripped, username, password string, err error) {
+	parts := strings.Split(urls, `,`)
+	for i, part := range parts {
+		u, err := url.Parse(normalizeURL(part))
+		if err != nil {
+			return ``, ``, ``, errors.WithPrevious(err, fmt.Sprintf(`invalid registry url [%s]`, part))
+		}
+
+		if u.User != nil && username == `` {
+			username = u.User.Username()
+			password, _ = u.User.Password()
+		}
+
+		u.User = nil
+		parts[i] = u.String()
+	}
+
+	return strings.Join(parts, `,`), username, password, nil
+}
+
+func propertyTLSConfig(props map[string]string) (*tls.Config, error) {
+	truststore, keystore := props[PropSSLTruststoreLocation], props[PropSSLKeystoreLocation]
+	if truststore == `` && keystore == `` {
+		return nil, nil
+	}
+
+	for _, prop := range []string{PropSSLTruststoreType, PropSSLKeystoreType} {
+		if storeType, ok := props[prop]; ok && !strings.EqualFold(storeType, `PEM`) {
+			return nil, errors.New(fmt.Sprintf(`unsupported %s [%s], only PEM stores are supported`, prop, storeType))
+		}
+	}
+
+	config := new(tls.Config)
+	if truststore != `` {
+		pem, err := os.ReadFile(truststore)
+		if err != nil {
+			return nil, errors.WithPrevious(err, fmt.Sprintf(`reading truststore %s failed`, truststore))
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New(fmt.Sprintf(`no certificates found in truststore %s`, truststore))
+		}
+	}
+
+	if keystore != `` {
+		// PEM keystores hold the certificate chain and the private key in the same file
+		pem, err := os.ReadFile(keystore)
+		if err != nil {
+			return nil, errors.WithPrevious(err, fmt.Sprintf(`reading keystore %s failed`, keystore))
+		}
+
+		cert, err := tls.X509KeyPair(pem, pem)
+		if err != nil {
+			return nil, errors.WithPrevious(err, fmt.Sprintf(`loading keystore %s failed`, keystore))
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// boolProperty parses an optional boolean property
+func boolProperty(props map[string]string, name string, defaultValue bool) (bool, error) {
+	value, ok := props[name]
+	if !ok {
+		return defaultValue, nil
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, errors.WithPrevious(err, fmt.Sprintf(`invalid %s [%s]`, name, value))
+	}
+
+	return b, nil
+}
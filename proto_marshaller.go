@@ -47,14 +47,19 @@ func (s *ProtoUnmarshaler) Unmarshal(in interface{}) error {
 }
 
 func (s *ProtoMarshaller) Marshall(v interface{}) ([]byte, error) {
+	return s.MarshallAppend(nil, v)
+}
+
+// MarshallAppend appends the anypb wrapped message to dst
+func (s *ProtoMarshaller) MarshallAppend(dst []byte, v interface{}) ([]byte, error) {
 	anyPB, err := anypb.New(v.(proto.Message))
 	if err != nil {
-		return nil, errors.WithPrevious(err, "failed to add message into anypb")
+		return dst, errors.WithPrevious(err, "failed to add message into anypb")
 	}
 
-	value, err := proto.Marshal(anyPB)
+	value, err := proto.MarshalOptions{}.MarshalAppend(dst, anyPB)
 	if err != nil {
-		return nil, errors.WithPrevious(err, "failed to marshal message into anypb")
+		return dst, errors.WithPrevious(err, "failed to marshal message into anypb")
 	}
 
 	return value, nil
@@ -0,0 +1,142 @@
+package schemaregistry
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimit configures client side limiting of registry requests
+type RateLimit struct {
+	RequestsPerSecond float64 // Token bucket refill rate, zero disables the token bucket
+	Burst             int     // Token bucket size (defaults to 1)
+	MaxConcurrent     int     // Maximum number of in-flight requests, zero means unlimited
+}
+
+// WithRateLimit limits the requests a Registry sends to the schema registry. The limit is shared by every call
+// the library makes, background sync requests only go through when no decode or register call is waiting
+func WithRateLimit(limit RateLimit) Option {
+	return func(options *Options) {
+		options.rateLimit = &limit
+	}
+}
+
+type requestPriority int
+
+const (
+	priorityDefault requestPriority = iota
+	priorityBackground
+)
+
+// limiter is a token bucket combined with a concurrency limit. Background requests yield to waiting default
+// priority requests
+type limiter struct {
+	rate          float64
+	burst         float64
+	tokens        float64
+	last          time.Time
+	maxConcurrent int
+	inflight      int
+	waiting       int // default priority requests waiting for a slot
+	notify        chan struct{}
+	mu            sync.Mutex
+}
+
+func newLimiter(limit *RateLimit) *limiter {
+	if limit == nil {
+		return nil
+	}
+
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &limiter{
+		rate:          limit.RequestsPerSecond,
+		burst:         burst,
+		tokens:        burst,
+		last:          time.Now(),
+		maxConcurrent: limit.MaxConcurrent,
+		notify:        make(chan struct{}),
+	}
+}
+
+// acquire blocks until the request is allowed to go through. The returned func must be called once the request
+// is completed
+func (l *limiter) acquire(priority requestPriority) (release func()) {
+	if l == nil {
+		return func() {}
+	}
+
+	l.mu.Lock()
+	waiting := false
+	for {
+		now := time.Now()
+		if l.rate > 0 {
+			l.tokens += now.Sub(l.last).Seconds() * l.rate
+			if l.tokens > l.burst {
+				l.tokens = l.burst
+			}
+		}
+		l.last = now
+
+		yield := priority == priorityBackground && l.waiting > 0
+		hasSlot := l.maxConcurrent <= 0 || l.inflight < l.maxConcurrent
+		hasToken := l.rate <= 0 || l.tokens >= 1
+		if !yield && hasSlot && hasToken {
+			if l.rate > 0 {
+				l.tokens--
+			}
+			l.inflight++
+			if waiting {
+				l.waiting--
+				l.broadcast()
+			}
+			l.mu.Unlock()
+
+			return l.release
+		}
+
+		if priority == priorityDefault && !waiting {
+			waiting = true
+			l.waiting++
+		}
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if !hasToken {
+			timer = time.NewTimer(time.Duration((1 - l.tokens) / l.rate * float64(time.Second)))
+			timeout = timer.C
+		}
+
+		notify := l.notify
+		l.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-timeout:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		l.mu.Lock()
+	}
+}
+
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+	l.broadcast()
+}
+
+// broadcast wakes up all waiting requests. Must be called while holding the lock
+func (l *limiter) broadcast() {
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
@@ -0,0 +1,91 @@
+package schemaregistry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit_MaxConcurrent(t *testing.T) {
+	var inflight, maxInflight int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInflight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInflight, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(`["test_subject"]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	reg, err := NewRegistry(srv.URL, WithRateLimit(RateLimit{MaxConcurrent: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := reg.client.GetSubjects(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if have := atomic.LoadInt32(&maxInflight); have > 2 {
+		t.Errorf(`need at most 2 concurrent requests, have %d`, have)
+	}
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	l := newLimiter(&RateLimit{RequestsPerSecond: 100, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		l.acquire(priorityDefault)()
+	}
+
+	// first request uses the burst token, the remaining four wait ~10ms each
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf(`need requests to be rate limited, took %s`, elapsed)
+	}
+}
+
+func TestLimiter_BackgroundYieldsToDefault(t *testing.T) {
+	l := newLimiter(&RateLimit{MaxConcurrent: 1})
+	release := l.acquire(priorityDefault)
+
+	order := make(chan requestPriority, 2)
+	wg := sync.WaitGroup{}
+	acquire := func(p requestPriority) {
+		defer wg.Done()
+		r := l.acquire(p)
+		order <- p
+		r()
+	}
+
+	wg.Add(1)
+	go acquire(priorityBackground)
+	time.Sleep(10 * time.Millisecond)
+
+	wg.Add(1)
+	go acquire(priorityDefault)
+	time.Sleep(10 * time.Millisecond)
+
+	release()
+	wg.Wait()
+
+	if first := <-order; first != priorityDefault {
+		t.Error(`need the default priority request to go first`)
+	}
+}
@@ -2,14 +2,15 @@ package schemaregistry
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
 	"github.com/tryfix/errors"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/olekukonko/tablewriter"
-
+	"github.com/hamba/avro/v2"
 	registry "github.com/riferrei/srclient"
 	"github.com/tryfix/log"
 )
@@ -45,8 +46,18 @@ type Subject struct {
 	Subject         string  // Subject where the subject is registered for
 	Version         Version // Version within this subject
 	Id              int     // Registry's unique id
+	SchemaType      registry.SchemaType
+	References      []registry.Reference
 	UnmarshalerFunc UnmarshalerFunc
 	marsheller      Marshaller
+	context         string
+	deleted         int32 // DeletedState
+	deletedWarned   int32
+	registeredAt    time.Time
+}
+
+func (s *Subject) key() schemaKey {
+	return schemaKey{context: s.context, id: s.Id}
 }
 
 func (s Subject) String() string {
@@ -58,19 +69,51 @@ type Options struct {
 		enabled      bool
 		syncInterval time.Duration
 	}
-	logger     log.Logger
-	mockClient *registry.MockSchemaRegistryClient
+	auth struct {
+		username    string
+		password    string
+		tokenSource TokenSource
+	}
+	failover struct {
+		urls     []string
+		strategy FailoverStrategy
+		cooldown time.Duration
+	}
+	schemaContext string
+	waitInterval  time.Duration
+	avroAPI       avro.API
+	deletedPolicy struct {
+		decode DeletedPolicy
+		encode DeletedPolicy
+	}
+	retryPolicy    RetryPolicy
+	circuitBreaker *CircuitBreakerConfig
+	rateLimit      *RateLimit
+	tlsConfig      *tls.Config
+	httpClient     *http.Client
+	logger         log.Logger
+	mockClient     *registry.MockSchemaRegistryClient
 }
 
 // Registry type holds schema registry details
 type Registry struct {
-	subjects     map[string]map[Version]*Subject
-	unmarshalers map[string]UnmarshalerFunc
-	idMap        map[int]*Subject
-	client       registry.ISchemaRegistryClient
-	mu           *sync.RWMutex
-	options      *Options
-	logger       log.Logger
+	subjects       map[string]map[Version]*Subject
+	unmarshalers   map[string]*subjectUnmarshalers
+	migrations     map[string]map[Version]migration
+	patterns       []registeredPattern
+	released       map[string]map[Version]bool // Versions removed using Unregister or UnregisterVersion
+	deletedChecks  map[string]string           // Version listing of each subject last checked by markDeleted
+	idMap          map[schemaKey]*Subject
+	pinned         map[schemaKey]*Subject // WithSchemaID subjects which are not registered, they cannot be decoded
+	client         registry.ISchemaRegistryClient
+	contextClients map[string]*registryClient
+	syncClient     registry.ISchemaRegistryClient
+	mu             *sync.RWMutex
+	options        *Options
+	avroAPI        avro.API
+	avroWriters    *sync.Pool
+	health         *healthState
+	logger         log.Logger
 }
 
 // Option is a type to host NewRegistry configurations
@@ -98,34 +141,76 @@ func WithMockClient(client *registry.MockSchemaRegistryClient) Option {
 	}
 }
 
-// NewRegistry returns a Registry instance
+// NewRegistry returns a Registry instance. url can hold a comma separated list of registry URLs, additional URLs
+// can also be added using WithURLs
 func NewRegistry(url string, opts ...Option) (*Registry, error) {
 	options := new(Options)
 	options.logger = log.NewNoopLogger()
 	options.backgroundSync.syncInterval = 10 * time.Second
+	options.failover.cooldown = defaultEndpointCooldown
+	options.avroAPI = avro.DefaultConfig
 
 	for _, opt := range opts {
 		opt(options)
 	}
 
-	if !(strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")) {
-		url = "http://" + url
+	logger := options.logger.NewLog(log.Prefixed(`SchemaRegistryClient`))
+
+	httpClient, err := newHTTPClient(options)
+	if err != nil {
+		return nil, errors.WithPrevious(err, `building registry http client failed`)
 	}
 
-	var client registry.ISchemaRegistryClient = registry.NewSchemaRegistryClient(url)
+	var urls []string
+	for _, u := range append(strings.Split(url, `,`), options.failover.urls...) {
+		if strings.TrimSpace(u) == `` {
+			continue
+		}
+
+		urls = append(urls, normalizeURL(u))
+	}
+
+	newClient := func(u string) registry.ISchemaRegistryClient {
+		client := registry.NewSchemaRegistryClient(u, registry.WithClient(httpClient))
+		// The Registry keeps its own cache, the client's cache would hide versions deleted in the registry
+		client.CachingEnabled(false)
+		return &versionsClient{SchemaRegistryClient: client, url: u, http: httpClient}
+	}
 
 	if options.mockClient != nil {
-		client = options.mockClient
+		urls = []string{options.mockClient.GetSchemaRegistryURL()}
+		newClient = func(string) registry.ISchemaRegistryClient {
+			return options.mockClient
+		}
 	}
 
+	if len(urls) == 0 {
+		return nil, errors.New(`at least one schema registry url is required`)
+	}
+
+	client := newRegistryClient(urls, newClient, options, logger)
+
 	r := &Registry{
-		subjects:     make(map[string]map[Version]*Subject),
-		unmarshalers: map[string]UnmarshalerFunc{},
-		idMap:        make(map[int]*Subject),
-		client:       client,
-		mu:           new(sync.RWMutex),
-		options:      options,
-		logger:       options.logger.NewLog(log.Prefixed(`SchemaRegistryClient`)),
+		subjects:       make(map[string]map[Version]*Subject),
+		unmarshalers:   map[string]*subjectUnmarshalers{},
+		migrations:     map[string]map[Version]migration{},
+		released:       map[string]map[Version]bool{},
+		deletedChecks:  map[string]string{},
+		idMap:          make(map[schemaKey]*Subject),
+		pinned:         map[schemaKey]*Subject{},
+		client:         client,
+		contextClients: map[string]*registryClient{},
+		syncClient:     client.withPriority(priorityBackground),
+		mu:             new(sync.RWMutex),
+		options:        options,
+		avroAPI:        options.avroAPI,
+		avroWriters:    avroWriterPool,
+		health:         new(healthState),
+		logger:         logger,
+	}
+
+	if options.avroAPI != avro.DefaultConfig {
+		r.avroWriters = newAvroWriterPool(options.avroAPI)
 	}
 
 	return r, nil
@@ -134,19 +219,25 @@ func NewRegistry(url string, opts ...Option) (*Registry, error) {
 // Register registers the given subject, version and UnmarshalerFunc in the Registry
 func (r *Registry) Register(subjectName string, version Version, unmarshalerFunc UnmarshalerFunc,
 	options ...RegisterOption) error {
-	if _, ok := r.subjects[subjectName]; ok {
-		if _, ok := r.subjects[subjectName][version]; ok {
-			r.logger.Warn(fmt.Sprintf(`Subject [%s][%s] already registred`, subjectName, version))
-		}
+	return r.register(priorityDefault, r.qualify(subjectName), version, unmarshalerFunc, options...)
+}
+
+// register fetches the schemas of the qualified subject with the given priority, the background sync passes its
+// lower priority
+func (r *Registry) register(priority requestPriority, subjectName string, version Version,
+	unmarshalerFunc UnmarshalerFunc, options ...RegisterOption) error {
+	client := r.client
+	if priority == priorityBackground {
+		client = r.syncClient
 	}
 
 	if version == VersionAll {
-		versions, err := r.client.GetSchemaVersions(subjectName)
+		versions, err := client.GetSchemaVersions(subjectName)
 		if err != nil {
 			return errors.WithPrevious(err, fmt.Sprintf(`Fetching schema versions for %s:%s failed.`, subjectName, version))
 		}
 		for _, v := range versions {
-			if err := r.Register(subjectName, Version(v), unmarshalerFunc, options...); err != nil {
+			if err := r.register(priority, subjectName, Version(v), unmarshalerFunc, options...); err != nil {
 				return err
 			}
 		}
@@ -155,14 +246,14 @@ func (r *Registry) Register(subjectName string, version Version, unmarshalerFunc
 
 	var clientSub *registry.Schema
 	if version == VersionLatest {
-		sub, err := r.client.GetLatestSchema(subjectName)
+		sub, err := client.GetLatestSchema(subjectName)
 		if err != nil {
 			return errors.WithPrevious(err, fmt.Sprintf(`Fetching latest schema for %s failed.`, subjectName))
 		}
 
 		clientSub = sub
 	} else {
-		sub, err := r.client.GetSchemaByVersion(subjectName, int(version))
+		sub, err := client.GetSchemaByVersion(subjectName, int(version))
 		if err != nil {
 			return errors.WithPrevious(err, fmt.Sprintf(`Fetching schema for %s:%s failed.`, subjectName, version))
 		}
@@ -170,15 +261,7 @@ func (r *Registry) Register(subjectName string, version Version, unmarshalerFunc
 		clientSub = sub
 	}
 
-	subject := &Subject{
-		Schema:          clientSub.Schema(),
-		Id:              clientSub.ID(),
-		Version:         Version(clientSub.Version()),
-		Subject:         subjectName,
-		UnmarshalerFunc: unmarshalerFunc,
-	}
-
-	subject.marsheller = r.getMarshaller(clientSub.SchemaType(), clientSub.Schema())
+	subject := r.newSubject(clientSub, subjectName, unmarshalerFunc, priority)
 
 	for _, option := range options {
 		option(subject)
@@ -188,13 +271,22 @@ func (r *Registry) Register(subjectName string, version Version, unmarshalerFunc
 		return errors.WithPrevious(err, fmt.Sprintf(`Initiating Marshaller for schema %s:%s failed.`, subject, version))
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if _, ok := r.subjects[subjectName]; !ok {
 		r.subjects[subjectName] = map[Version]*Subject{}
 	}
 
-	r.unmarshalers[subjectName] = unmarshalerFunc
-	r.subjects[subjectName][version] = subject
-	r.idMap[clientSub.ID()] = subject
+	// Subjects are indexed by their concrete version, VersionLatest registrations included
+	if _, ok := r.subjects[subjectName][subject.Version]; ok {
+		r.logger.Warn(fmt.Sprintf(`Subject [%s][%s] already registred`, subjectName, subject.Version))
+	}
+
+	r.clearReleased(subjectName, subject.Version)
+	r.subjectUnmarshalers(subjectName).versions[subject.Version] = unmarshalerFunc
+	r.subjects[subjectName][subject.Version] = subject
+	r.idMap[subject.key()] = subject
 
 	r.logger.Info(fmt.Sprintf(`Subject %s registred`, subject))
 
@@ -215,10 +307,17 @@ func (r *Registry) Sync() error {
 	return nil
 }
 
-// WithSchema return the specific encoder which registered at the initialization under the subject and version
+// WithSchema return the specific encoder which registered at the initialization under the subject and version.
+// VersionLatest and VersionAll resolve to the latest registered version
 func (r *Registry) WithSchema(subject string, version Version) Encoder {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	if version == VersionLatest || version == VersionAll {
+		return r.WithLatestSchema(subject)
+	}
+
+	subject = r.qualify(subject)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	e, ok := r.subjects[subject][version]
 	if !ok {
@@ -228,85 +327,208 @@ func (r *Registry) WithSchema(subject string, version Version) Encoder {
 	return NewRegistryEncoder(r, e)
 }
 
-// WithLatestSchema returns the latest event version encoder registered under given subject
-func (r *Registry) WithLatestSchema(subject string) Encoder {
+// WithSchemaID returns an encoder pinned to the given schema ID of the Registry's schema context. Schemas which are
+// not yet cached are fetched from the registry. Messages of subjects which are not registered can be encoded but not
+// decoded since there is no UnmarshalerFunc for them
+func (r *Registry) WithSchemaID(schemaID int) (Encoder, error) {
+	subject, err := r.subjectBySchemaID(schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegistryEncoder(r, subject), nil
+}
+
+// subjectBySchemaID returns the cached subject of the schema ID, fetching it from the registry when missing
+func (r *Registry) subjectBySchemaID(schemaID int) (*Subject, error) {
+	if subject, ok := r.getSubjectBySchemaID(r.options.schemaContext, schemaID); ok {
+		return subject, nil
+	}
+
+	r.mu.RLock()
+	pinned, ok := r.pinned[schemaKey{context: r.options.schemaContext, id: schemaID}]
+	r.mu.RUnlock()
+
+	if ok && !r.subjectRegistered(pinned.Subject) {
+		return pinned, nil
+	}
+
+	schema, subjectName, err := r.fetchSchemaByID(r.options.schemaContext, schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.subjectRegistered(subjectName) {
+		return r.addSubjectBySchema(schema, subjectName, priorityDefault)
+	}
+
+	subject := r.newSubject(schema, subjectName, nil, priorityDefault)
+	if err := subject.marsheller.Init(); err != nil {
+		return nil, errors.WithPrevious(err, fmt.Sprintf(`Initiating Marshaller for schema %s failed.`, subject))
+	}
+
+	// Kept out of idMap, Decode would find the subject without an UnmarshalerFunc once it gets registered
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.pinned[subject.key()] = subject
+
+	return subject, nil
+}
+
+// WithLatestSchema returns the latest event version encoder registered under given subject
+func (r *Registry) WithLatestSchema(subject string) Encoder {
+	subject = r.qualify(subject)
 
-	versions, ok := r.subjects[subject]
+	latest, ok := r.latestSubject(subject)
 	if !ok {
 		panic(fmt.Sprintf(`schemaregistry.registry: unregistred subject [%s]`, subject))
 	}
-	var v Version
-	for _, version := range versions {
-		if version.Version > v {
-			v = version.Version
-		}
-	}
 
-	return NewRegistryEncoder(r, versions[v])
+	return NewRegistryEncoder(r, latest)
 }
 
 // GenericEncoder returns a placeholder encoder for decoders.
 // It can be used to decode any schema version for registered subjects without explicitly mentioning
 // the Subject:Version combination
 func (r *Registry) GenericEncoder() Encoder {
-	return &GenericEncoder{NewRegistryEncoder(r, nil)}
+	return &GenericEncoder{&RegistryEncoder{registry: r, upcast: true, schemaContext: r.options.schemaContext}}
 }
 
-func (r *Registry) getSubjectBySchemaID(schemaID int) (subject *Subject, ok bool) {
+func (r *Registry) getSubjectBySchemaID(context string, schemaID int) (subject *Subject, ok bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	subject, ok = r.idMap[schemaID]
+	subject, ok = r.idMap[schemaKey{context: context, id: schemaID}]
 
 	return
 }
 
-func (r *Registry) getUnMarshallerFunc(subjectName string) UnmarshalerFunc {
+func (r *Registry) getUnMarshallerFunc(subjectName string, version Version) (UnmarshalerFunc, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	unmarshallar, ok := r.unmarshalers[subjectName]
+	u, ok := r.unmarshalers[subjectName]
 	if !ok {
-		panic(fmt.Sprintf(`un marshaller doesn't exists for subject %s`, subjectName))
+		return nil, errors.New(fmt.Sprintf(`un marshaller doesn't exists for subject %s`, subjectName))
 	}
 
-	return unmarshallar
-}
+	unmarshallar, ok := u.resolve(version)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(`un marshaller doesn't exists for subject %s:%s`, subjectName, version))
+	}
 
-func (r *Registry) getMarshaller(schemaType *registry.SchemaType, schema string) Marshaller {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	return unmarshallar, nil
+}
 
-	var marshaller Marshaller = NewAvroMarshaller(schema)
+func (r *Registry) getMarshaller(client registry.ISchemaRegistryClient, schemaType *registry.SchemaType,
+	schema string, references []registry.Reference) Marshaller {
 	if schemaType != nil && *schemaType == registry.Protobuf {
-		marshaller = NewProtoMarshaller()
+		return NewProtoMarshaller()
+	}
+
+	marshaller := NewAvroMarshaller(schema)
+	marshaller.api = r.avroAPI
+	marshaller.writers = r.avroWriters
+	if len(references) > 0 {
+		marshaller.references = func() ([]string, error) {
+			return r.referencedSchemas(client, references)
+		}
 	}
 
 	return marshaller
 }
 
-func (r *Registry) addSubjectBySchema(schema *registry.Schema, subjectName string) error {
+// referencedSchemas fetches the referenced schemas and their own references using the client of the referencing
+// subject, dependencies come first
+func (r *Registry) referencedSchemas(client registry.ISchemaRegistryClient,
+	references []registry.Reference) ([]string, error) {
+	var schemas []string
+	seen := map[registry.Reference]bool{}
+
+	var visit func(references []registry.Reference) error
+	visit = func(references []registry.Reference) error {
+		for _, reference := range references {
+			key := registry.Reference{Subject: reference.Subject, Version: reference.Version}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			schema, err := client.GetSchemaByVersion(reference.Subject, reference.Version)
+			if err != nil {
+				return errors.WithPrevious(err, fmt.Sprintf(`fetching reference %s:%d failed`,
+					reference.Subject, reference.Version))
+			}
+
+			if err := visit(schema.References()); err != nil {
+				return err
+			}
+
+			schemas = append(schemas, schema.Schema())
+		}
+
+		return nil
+	}
+
+	return schemas, visit(references)
+}
+
+// newSubject builds the Subject for a schema fetched from the registry. The Marshaller is created but not initiated,
+// references are fetched from the subject's schema context with the given priority
+func (r *Registry) newSubject(schema *registry.Schema, subjectName string, unmarshalerFunc UnmarshalerFunc,
+	priority requestPriority) *Subject {
+	schemaType := registry.Avro
+	if schema.SchemaType() != nil {
+		schemaType = *schema.SchemaType()
+	}
+
+	context, _ := SplitSubject(subjectName)
 	subject := &Subject{
+		Schema:          schema.Schema(),
 		Subject:         subjectName,
 		Version:         Version(schema.Version()),
-		Schema:          schema.Schema(),
 		Id:              schema.ID(),
-		UnmarshalerFunc: r.getUnMarshallerFunc(subjectName),
+		SchemaType:      schemaType,
+		References:      schema.References(),
+		UnmarshalerFunc: unmarshalerFunc,
+		context:         context,
+		registeredAt:    time.Now(),
 	}
 
-	subject.marsheller = r.getMarshaller(schema.SchemaType(), subject.Schema)
+	subject.marsheller = r.getMarshaller(r.clientFor(context, priority), schema.SchemaType(), subject.Schema,
+		subject.References)
+
+	return subject
+}
+
+func (r *Registry) addSubjectBySchema(schema *registry.Schema, subjectName string,
+	priority requestPriority) (*Subject, error) {
+	unmarshalerFunc, err := r.getUnMarshallerFunc(subjectName, Version(schema.Version()))
+	if err != nil {
+		return nil, err
+	}
+
+	subject := r.newSubject(schema, subjectName, unmarshalerFunc, priority)
 	if err := subject.marsheller.Init(); err != nil {
-		return errors.WithPrevious(err, fmt.Sprintf(`Initiating Marshaller for schema %s:%d failed.`, subject, schema.Version()))
+		return nil, errors.WithPrevious(err, fmt.Sprintf(`Initiating Marshaller for schema %s:%d failed.`, subject, schema.Version()))
 	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
+
+	// The subject may have been unregistered while the schema was fetched
+	if _, ok := r.subjects[subject.Subject]; !ok {
+		return nil, errors.New(fmt.Sprintf(`subject %s not registered`, subject.Subject))
+	}
+
+	if released := r.released[subject.Subject]; released[VersionAll] || released[subject.Version] {
+		return nil, errors.New(fmt.Sprintf(`subject %s unregistered`, subject))
+	}
+
 	r.subjects[subject.Subject][subject.Version] = subject
-	r.idMap[subject.Id] = subject
+	r.idMap[subject.key()] = subject
 
-	return nil
+	return subject, nil
 }
 
 func (r *Registry) subjectRegistered(subject string) bool {
@@ -322,28 +544,39 @@ func (r *Registry) hasVersion(subject string, version Version) bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	_, ok := r.subjects[subject]
-	if !ok {
-		panic(fmt.Sprintf(`subject %s not registered`, subject))
-	}
-
 	_, versionExists := r.subjects[subject][version]
 
 	return versionExists
 }
 
-func (r *Registry) updateRegistryCache(schemaID int) error {
-	schema, err := r.client.GetSchema(schemaID)
+// fetchSchemaByID fetches the schema and the qualified name of the subject it belongs to from the registry
+func (r *Registry) fetchSchemaByID(context string, schemaID int) (*registry.Schema, string, error) {
+	done := r.trackLookup()
+	defer done()
+
+	client := r.clientFor(context, priorityDefault)
+	schema, err := client.GetSchema(schemaID)
 	if err != nil {
-		return errors.WithPrevious(err, fmt.Sprintf(`fetch schema failed for Schama ID: %d`, schemaID))
+		return nil, ``, errors.WithPrevious(err, fmt.Sprintf(`fetch schema failed for Schama ID: %d`, schemaID))
 	}
 
-	resp, err := r.client.GetSubjectVersionsById(schemaID)
+	resp, err := client.GetSubjectVersionsById(schemaID)
 	if err != nil {
-		return errors.WithPrevious(err, fmt.Sprintf(`fetch schema failed for Schama ID: %d`, schemaID))
+		return nil, ``, errors.WithPrevious(err, fmt.Sprintf(`fetch schema failed for Schama ID: %d`, schemaID))
+	}
+
+	if len(resp) == 0 {
+		return nil, ``, errors.New(fmt.Sprintf(`no subjects found for Schama ID: %d`, schemaID))
 	}
 
-	subjectname := resp[0].Subject
+	return schema, QualifySubject(context, resp[0].Subject), nil
+}
+
+func (r *Registry) updateRegistryCache(context string, schemaID int) error {
+	schema, subjectname, err := r.fetchSchemaByID(context, schemaID)
+	if err != nil {
+		return err
+	}
 
 	// Check if subject is registered
 	if !r.subjectRegistered(subjectname) {
@@ -351,36 +584,21 @@ func (r *Registry) updateRegistryCache(schemaID int) error {
 			`Schema ID - %d cannot be added to the Registry. Subject %s not registered`, schemaID, subjectname))
 	}
 
-	return r.addSubjectBySchema(schema, subjectname)
+	_, err = r.addSubjectBySchema(schema, subjectname, priorityDefault)
+
+	return err
 }
 
+// Print logs the given subject, or all registered subjects when nil, as a table
 func (r *Registry) Print(subject *Subject) {
 	b := new(bytes.Buffer)
-	table := tablewriter.NewWriter(b)
-	table.SetHeader([]string{`Schema Id`, `subject`, `version`, `decoderFunc`})
-	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT})
-	table.SetAutoFormatHeaders(true)
-
-	appendRow := func(subject *Subject) {
-		table.Append([]string{
-			fmt.Sprint(subject.Id),
-			fmt.Sprint(subject.Subject),
-			fmt.Sprint(subject.Version),
-			fmt.Sprint(subject.UnmarshalerFunc != nil),
-		})
-	}
-
 	if subject != nil {
-		appendRow(subject)
-	} else {
-		for _, versions := range r.subjects {
-			for _, version := range versions {
-				appendRow(version)
-			}
-		}
+		renderTable(b, []exportRecord{newExportRecord(subject.snapshot())})
+	} else if err := r.Export(b, ExportTable); err != nil {
+		r.logger.Error(fmt.Sprintf(`Printing schemas failed due to %s`, err))
+		return
 	}
 
-	table.Render()
 	r.logger.Info(fmt.Sprintf("Schemas\n%s", b.String()))
 }
 
@@ -1,6 +1,7 @@
 package schemaregistry
 
 import (
+	"encoding/binary"
 	registry "github.com/riferrei/srclient"
 	"github.com/tryfix/log"
 	"os"
@@ -50,12 +51,12 @@ type mockRegistry struct {
 	client *registry.MockSchemaRegistryClient
 }
 
-func setupMockRegistry(bgSyncInterval time.Duration) mockRegistry {
+func setupMockRegistry(bgSyncInterval time.Duration, opts ...Option) mockRegistry {
 	mockClient := registry.CreateMockSchemaRegistryClient(`test`)
-	reg, err := NewRegistry(`mock`, WithMockClient(mockClient),
+	reg, err := NewRegistry(`mock`, append([]Option{WithMockClient(mockClient),
 		WithLogger(log.Constructor.Log(log.WithColors(false))),
 		WithBackgroundSync(bgSyncInterval),
-	)
+	}, opts...)...)
 	if err != nil {
 		panic(err)
 	}
@@ -66,6 +67,20 @@ func setupMockRegistry(bgSyncInterval time.Duration) mockRegistry {
 	}
 }
 
+// setupSampleRegistry returns a mock registry holding the avro_v1 and avro_v2 test schemas as versions 1 and 2
+// (schema IDs 100 and 101) of test_subject
+func setupSampleRegistry(t testing.TB, opts ...Option) mockRegistry {
+	t.Helper()
+	reg := setupMockRegistry(1, opts...)
+	for version, schema := range []string{testSchemas[`avro_v1`], testSchemas[`avro_v2`]} {
+		if _, err := reg.client.SetSchema(100+version, `test_subject`, schema, registry.Avro, version+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return reg
+}
+
 func TestRegistry_GenericEncoder(t *testing.T) {
 	reg := setupMockRegistry(1)
 	_, err := reg.client.SetSchema(100, `test_subject`, testSchemas[`avro_v1`], registry.Avro, 1)
@@ -392,3 +407,192 @@ func TestWithBackgroundSync(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestRegistryEncoder_EncodeAppend(t *testing.T) {
+	reg := setupMockRegistry(1)
+	_, err := reg.client.SetSchema(100, `test_subject`, testSchemas[`avro_v1`], registry.Avro, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, 1, func(unmarshaler Unmarshaler) (interface{}, error) {
+		v := SampleV1{}
+		if err := unmarshaler.Unmarshal(&v); err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	v := &SampleV1{
+		Field1: 100,
+		Field2: 10.11,
+		Field3: "text",
+	}
+	encoder := reg.WithSchema(`test_subject`, 1)
+	byt, err := encoder.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := append(make([]byte, 0, 256), `header`...)
+	buf, err = encoder.(AppendEncoder).EncodeAppend(buf, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(buf[:6]) != `header` || !reflect.DeepEqual(buf[6:], byt) {
+		t.Errorf(`need %v, have %v`, byt, buf[6:])
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = encoder.(AppendEncoder).EncodeAppend(buf[:0], v)
+	})
+	if allocs > 0 {
+		t.Errorf(`need zero allocations, have %v`, allocs)
+	}
+}
+
+func TestRegistry_WithSchemaID(t *testing.T) {
+	reg := setupMockRegistry(1)
+	_, err := reg.client.SetSchema(100, `test_subject`, testSchemas[`avro_v1`], registry.Avro, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = reg.client.SetSchema(101, `test_subject`, testSchemas[`avro_v2`], registry.Avro, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, 1, func(unmarshaler Unmarshaler) (interface{}, error) {
+		v := SampleV2{}
+		if err := unmarshaler.Unmarshal(&v); err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// version 2 is not cached yet
+	encoder, err := reg.WithSchemaID(101)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := SampleV2{
+		Field1: 100,
+		Field2: 10.11,
+		Field3: "text",
+		Field4: "text 2",
+	}
+	byt, err := encoder.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := binary.BigEndian.Uint32(byt[1:5]); id != 101 {
+		t.Errorf(`need schema id 101, have %d`, id)
+	}
+
+	vOut, err := encoder.Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(v, vOut) {
+		t.Errorf(`need %v, have %v`, v, vOut)
+	}
+}
+
+func TestRegistry_WithSchemaIDUnregisteredSubject(t *testing.T) {
+	reg := setupMockRegistry(1)
+	_, err := reg.client.SetSchema(500, `test_subject_other`, testSchemas[`avro_v1`], registry.Avro, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoder, err := reg.WithSchemaID(500)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byt, err := encoder.Encode(SampleV1{Field1: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := encoder.Decode(byt); err == nil {
+		t.Fatal(`expected error decoding an unregistered subject`)
+	}
+
+	if _, err := reg.WithSchemaID(600); err == nil {
+		t.Fatal(`expected error for an unknown schema id`)
+	}
+}
+
+func TestRegistry_RegisterLatestVersion(t *testing.T) {
+	reg := setupSampleRegistry(t)
+
+	if err := reg.Register(`test_subject`, VersionLatest, NewUnmarshalerFunc[SampleV2]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reg.hasVersion(`test_subject`, 2) {
+		t.Error(`need the latest registration to be indexed by its version`)
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	// The sync adds the older version only
+	versions := reg.Versions(`test_subject`)
+	if len(versions) != 2 || versions[0].Version != 1 || versions[1].Version != 2 {
+		t.Errorf(`need versions 1 and 2 to be registered once, have %+v`, versions)
+	}
+
+	for _, encoder := range []Encoder{
+		reg.WithSchema(`test_subject`, 2),
+		reg.WithSchema(`test_subject`, VersionLatest),
+		reg.WithLatestSchema(`test_subject`),
+	} {
+		byt, err := encoder.Encode(SampleV2{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id := binary.BigEndian.Uint32(byt[1:prefixLen]); id != 101 {
+			t.Errorf(`need schema ID 101, have %d`, id)
+		}
+	}
+}
+
+func TestRegistry_WithSchemaIDRegisteredLater(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	encoder, err := reg.WithSchemaID(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byt, err := encoder.Encode(SampleV1{Field3: `pinned`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, 2, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := reg.GenericEncoder().Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out, ok := v.(SampleV1); !ok || out.Field3 != `pinned` {
+		t.Errorf(`need the message to be decoded using the registered UnmarshalerFunc, have %+v`, v)
+	}
+}
@@ -0,0 +1,188 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"sync"
+
+	registry "github.com/riferrei/srclient"
+	"github.com/tryfix/errors"
+)
+
+// Remapper rewrites the schema IDs of messages encoded against the source Registry into the IDs of the same schemas
+// in the target Registry (ex: topics replicated into a DR cluster). Only the header is rewritten, payloads are not
+// re-encoded
+type Remapper struct {
+	source            *Registry
+	target            *Registry
+	allowRegistration bool
+	ids               map[int]int // source ID -> target ID
+	mu                sync.RWMutex
+}
+
+// NewRemapper returns a Remapper from source to target. When allowRegistration is set schemas missing in the target
+// are registered under the source subject
+func NewRemapper(source, target *Registry, allowRegistration bool) *Remapper {
+	return &Remapper{
+		source:            source,
+		target:            target,
+		allowRegistration: allowRegistration,
+		ids:               map[int]int{},
+	}
+}
+
+// Remap returns a copy of the message with the source schema ID replaced by the target schema ID
+func (m *Remapper) Remap(data []byte) ([]byte, error) {
+	if len(data) < prefixLen {
+		return nil, errors.New(`message length is zero`)
+	}
+
+	targetID, err := m.TargetID(int(binary.BigEndian.Uint32(data[1:prefixLen])))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(data))
+	copy(out, data)
+	binary.BigEndian.PutUint32(out[1:prefixLen], uint32(targetID))
+
+	return out, nil
+}
+
+// TargetID returns the target schema ID of the source schema ID. Resolved pairs are cached
+func (m *Remapper) TargetID(sourceID int) (int, error) {
+	m.mu.RLock()
+	targetID, ok := m.ids[sourceID]
+	m.mu.RUnlock()
+
+	if ok {
+		return targetID, nil
+	}
+
+	subject, schema, schemaType, references, err := m.sourceSchema(sourceID)
+	if err != nil {
+		return 0, err
+	}
+
+	context, name := SplitSubject(subject)
+	subject = m.target.qualify(name)
+
+	references, err = m.targetReferences(context, references)
+	if err != nil {
+		return 0, errors.WithPrevious(err, fmt.Sprintf(`remapping references of schema ID [%d] failed`, sourceID))
+	}
+
+	targetSchema, err := m.targetSchema(subject, schema, schemaType, references)
+	if err != nil {
+		return 0, errors.WithPrevious(err, fmt.Sprintf(`remapping schema ID [%d] failed`, sourceID))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ids[sourceID] = targetSchema.ID()
+
+	return targetSchema.ID(), nil
+}
+
+// targetSchema looks up the schema under the subject in the target registry, or registers it when allowed. The
+// references must already point to the target registry
+func (m *Remapper) targetSchema(subject, schema string, schemaType registry.SchemaType,
+	references []registry.Reference) (*registry.Schema, error) {
+	targetSchema, err := m.target.client.LookupSchema(subject, schema, schemaType, references...)
+	if err == nil {
+		return targetSchema, nil
+	}
+
+	if statusCode(err) != http.StatusNotFound || !m.allowRegistration {
+		return nil, errors.WithPrevious(err, fmt.Sprintf(`schema of subject %s not found in target registry`, subject))
+	}
+
+	targetSchema, err = m.target.client.CreateSchema(subject, schema, schemaType, references...)
+	if err != nil {
+		return nil, errors.WithPrevious(err, fmt.Sprintf(`registering schema under subject %s in target registry failed`,
+			subject))
+	}
+
+	m.target.logger.Info(fmt.Sprintf(`Schema registered as [%d] under subject %s`, targetSchema.ID(), subject))
+
+	return targetSchema, nil
+}
+
+// targetReferences returns the references with the subjects and versions of the referenced schemas in the target
+// registry, missing referenced schemas are registered when allowed. Source references are resolved in the source
+// schema context
+func (m *Remapper) targetReferences(sourceContext string, references []registry.Reference) ([]registry.Reference,
+	error) {
+	if len(references) == 0 {
+		return references, nil
+	}
+
+	client := m.source.clientFor(sourceContext, priorityDefault)
+	remapped := make([]registry.Reference, 0, len(references))
+	for _, reference := range references {
+		referenced, err := client.GetSchemaByVersion(reference.Subject, reference.Version)
+		if err != nil {
+			return nil, errors.WithPrevious(err, fmt.Sprintf(`fetching reference %s:%d failed`,
+				reference.Subject, reference.Version))
+		}
+
+		schemaType := registry.Avro
+		if referenced.SchemaType() != nil {
+			schemaType = *referenced.SchemaType()
+		}
+
+		context, name := SplitSubject(reference.Subject)
+		if context == `` {
+			context = sourceContext
+		}
+
+		nested, err := m.targetReferences(context, referenced.References())
+		if err != nil {
+			return nil, err
+		}
+
+		subject := m.target.qualify(name)
+		targetSchema, err := m.targetSchema(subject, referenced.Schema(), schemaType, nested)
+		if err != nil {
+			return nil, err
+		}
+
+		// Registered schemas are returned without their version
+		if targetSchema.Version() == 0 {
+			targetSchema, err = m.target.client.LookupSchema(subject, referenced.Schema(), schemaType, nested...)
+			if err != nil {
+				return nil, errors.WithPrevious(err, fmt.Sprintf(`looking up reference %s in target registry failed`,
+					subject))
+			}
+		}
+
+		remapped = append(remapped, registry.Reference{
+			Name:    reference.Name,
+			Subject: name,
+			Version: targetSchema.Version(),
+		})
+	}
+
+	return remapped, nil
+}
+
+// sourceSchema returns the schema of the source ID from the source Registry's cache or the source registry
+func (m *Remapper) sourceSchema(sourceID int) (subject, schema string, schemaType registry.SchemaType,
+	references []registry.Reference, err error) {
+	if s, ok := m.source.getSubjectBySchemaID(m.source.options.schemaContext, sourceID); ok {
+		return s.Subject, s.Schema, s.SchemaType, s.References, nil
+	}
+
+	fetched, subject, err := m.source.fetchSchemaByID(m.source.options.schemaContext, sourceID)
+	if err != nil {
+		return ``, ``, ``, nil, err
+	}
+
+	schemaType = registry.Avro
+	if fetched.SchemaType() != nil {
+		schemaType = *fetched.SchemaType()
+	}
+
+	return subject, fetched.Schema(), schemaType, fetched.References(), nil
+}
@@ -0,0 +1,158 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	registry "github.com/riferrei/srclient"
+)
+
+func setupTargetRegistry(t *testing.T, registered *int32) *Registry {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == `/subjects/test_subject/versions`:
+			atomic.StoreInt32(registered, 1)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{`id`: 200})
+			return
+		case r.Method == http.MethodPost && r.URL.Path == `/subjects/test_subject` && atomic.LoadInt32(registered) == 1,
+			r.Method == http.MethodGet && r.URL.Path == `/schemas/ids/200`:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				`subject`: `test_subject`,
+				`version`: 1,
+				`id`:      200,
+				`schema`:  testSchemas[`avro_v1`],
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := NewRegistry(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return target
+}
+
+func TestRemapper_Remap(t *testing.T) {
+	source := setupSampleRegistry(t)
+	if err := source.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	byt, err := source.WithSchema(`test_subject`, 1).Encode(SampleV1{Field1: 1, Field3: `remap`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var registered int32
+	target := setupTargetRegistry(t, &registered)
+
+	if _, err := NewRemapper(source.Registry, target, false).Remap(byt); err == nil {
+		t.Fatal(`need an error when registration is not allowed`)
+	}
+
+	remapper := NewRemapper(source.Registry, target, true)
+	remapped, err := remapper.Remap(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := binary.BigEndian.Uint32(remapped[1:prefixLen]); id != 200 {
+		t.Errorf(`need target schema ID 200, have %d`, id)
+	}
+
+	if string(remapped[prefixLen:]) != string(byt[prefixLen:]) {
+		t.Error(`need payload to be unchanged`)
+	}
+
+	if id := binary.BigEndian.Uint32(byt[1:prefixLen]); id != 100 {
+		t.Errorf(`need source message to be unchanged, have schema ID %d`, id)
+	}
+
+	// Lookups of the same schema ID are served from the cache
+	remapper.target = nil
+	if _, err := remapper.Remap(byt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemapper_References(t *testing.T) {
+	source := setupSampleRegistry(t)
+	if _, err := source.client.SetSchema(102, `sample_record`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mock client cannot resolve references, the referencing schema is cached in the source Registry
+	envelope := &Subject{
+		Schema: `{
+			"type": "record",
+			"name": "Envelope",
+			"fields": [{"name": "sample", "type": "com.mycorp.mynamespace.SampleRecord"}]
+		}`,
+		Subject:    `envelope`,
+		Version:    1,
+		Id:         110,
+		SchemaType: registry.Avro,
+		References: []registry.Reference{
+			{Name: `com.mycorp.mynamespace.SampleRecord`, Subject: `sample_record`, Version: 1},
+		},
+	}
+	source.idMap[envelope.key()] = envelope
+
+	var registered int32
+	var references []registry.Reference
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == `/subjects/sample_record/versions`:
+			atomic.StoreInt32(&registered, 1)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{`id`: 201})
+			return
+		case r.Method == http.MethodPost && r.URL.Path == `/subjects/sample_record` && atomic.LoadInt32(&registered) == 1:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				`subject`: `sample_record`, `version`: 3, `id`: 201, `schema`: testSchemas[`avro_v1`]})
+			return
+		case r.Method == http.MethodPost && r.URL.Path == `/subjects/envelope/versions`:
+			req := struct {
+				References []registry.Reference `json:"references"`
+			}{}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			references = req.References
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{`id`: 202})
+			return
+		case r.Method == http.MethodGet && (r.URL.Path == `/schemas/ids/201` || r.URL.Path == `/schemas/ids/202`):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{`schema`: testSchemas[`avro_v1`]})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := NewRegistry(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targetID, err := NewRemapper(source.Registry, target, true).TargetID(110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if targetID != 202 {
+		t.Errorf(`need target schema ID 202, have %d`, targetID)
+	}
+
+	if len(references) != 1 || references[0].Subject != `sample_record` || references[0].Version != 3 {
+		t.Errorf(`need the reference to point to version 3 in the target, have %+v`, references)
+	}
+}
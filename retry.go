@@ -0,0 +1,182 @@
+package schemaregistry
+
+import (
+	stderrors "errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the registry while the circuit breaker is open
+var ErrCircuitOpen = stderrors.New(`schemaregistry: circuit breaker is open, registry calls are suspended`)
+
+// RetryPolicy configures how failed registry calls are retried. Network errors are always considered retryable,
+// registry errors only when their HTTP status is listed in RetryableStatusCodes
+type RetryPolicy struct {
+	MaxAttempts          int           // Total number of attempts including the first call
+	InitialBackoff       time.Duration // Wait time before the first retry
+	MaxBackoff           time.Duration // Upper bound of the wait time between retries
+	Multiplier           float64       // Factor the backoff grows by after each retry
+	Jitter               float64       // Fraction (0-1) of the backoff randomised to spread retries
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryPolicy returns a RetryPolicy suitable for riding out short registry outages
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy retries failed registry calls made by Register, the background sync and Decode lookups
+// according to the given policy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(options *Options) {
+		options.retryPolicy = policy
+	}
+}
+
+// CircuitBreakerConfig configures the circuit breaker around registry calls
+type CircuitBreakerConfig struct {
+	FailureThreshold int           // Consecutive failed calls after which the circuit opens
+	OpenTimeout      time.Duration // How long the circuit stays open before a trial call is let through
+}
+
+// WithCircuitBreaker stops calling the registry once it keeps failing. While the circuit is open all calls
+// (ex: Decode lookups of uncached schema IDs) fail immediately with ErrCircuitOpen
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(options *Options) {
+		options.circuitBreaker = &config
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if code := statusCode(err); code != 0 {
+		for _, c := range p.RetryableStatusCodes {
+			if c == code {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return isEndpointFailure(err)
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= p.Multiplier
+	}
+
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(d)
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type circuitBreaker struct {
+	config    CircuitBreakerConfig
+	state     breakerState
+	failures  int
+	openUntil time.Time
+	mu        sync.Mutex
+}
+
+func newCircuitBreaker(config *CircuitBreakerConfig) *circuitBreaker {
+	if config == nil {
+		return nil
+	}
+
+	return &circuitBreaker{config: *config}
+}
+
+// allow reports whether a call can go through. Once the open timeout has passed a single trial call is allowed
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Now().Before(b.openUntil) {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	}
+
+	return true
+}
+
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil || !isEndpointFailure(err) {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.state = breakerOpen
+		b.openUntil = time.Now().Add(b.config.OpenTimeout)
+	}
+}
+
+// withRetry calls fn until it succeeds, returns a non retryable error or runs out of attempts
+func withRetry[T any](c *registryClient, fn func() (T, error)) (T, error) {
+	policy := c.retryPolicy
+	attempt := 1
+	for {
+		res, err := fn()
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			return res, err
+		}
+
+		wait := policy.backoff(attempt)
+		c.logger.Warn(fmt.Sprintf(`Registry call failed (attempt %d of %d), retrying in %s due to %s`,
+			attempt, policy.MaxAttempts, wait, err))
+		time.Sleep(wait)
+		attempt++
+	}
+}
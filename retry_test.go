@@ -0,0 +1,107 @@
+package schemaregistry
+
+import (
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetryPolicy(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte(`["test_subject"]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	reg, err := NewRegistry(srv.URL, WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.client.GetSubjects(); err != nil {
+		t.Fatal(err)
+	}
+
+	if have := atomic.LoadInt32(&hits); have != 3 {
+		t.Errorf(`need 3 attempts, have %d`, have)
+	}
+}
+
+func TestWithRetryPolicy_NonRetryableStatus(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	reg, err := NewRegistry(srv.URL, WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.client.GetSchemaVersions(`test_subject`); err == nil {
+		t.Fatal(`expected error`)
+	}
+
+	if have := atomic.LoadInt32(&hits); have != 1 {
+		t.Errorf(`need 1 attempt, have %d`, have)
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+
+	reg, err := NewRegistry(srv.URL, WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := reg.client.GetSchema(100); err == nil || stderrors.Is(err, ErrCircuitOpen) {
+			t.Fatalf(`expected registry error, have %v`, err)
+		}
+	}
+
+	// decoding an uncached schema ID fails fast while the circuit is open
+	_, err = reg.GenericEncoder().Decode([]byte{0, 0, 0, 0, 100, 1})
+	if err == nil {
+		t.Fatal(`expected error`)
+	}
+
+	if have := atomic.LoadInt32(&hits); have != 2 {
+		t.Errorf(`need 2 calls to the registry, have %d`, have)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	// trial call after the open timeout
+	if _, err := reg.client.GetSchema(100); stderrors.Is(err, ErrCircuitOpen) {
+		t.Fatal(`expected a trial call after the open timeout`)
+	}
+
+	if have := atomic.LoadInt32(&hits); have != 3 {
+		t.Errorf(`need 3 calls to the registry, have %d`, have)
+	}
+}
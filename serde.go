@@ -0,0 +1,298 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	registry "github.com/riferrei/srclient"
+	"github.com/tryfix/errors"
+)
+
+// SubjectNameStrategy derives the subject a record is serialized under
+type SubjectNameStrategy func(topic string, isKey bool, recordName string) (string, error)
+
+// TopicNameStrategy uses <topic>-key or <topic>-value as the subject
+func TopicNameStrategy(topic string, isKey bool, _ string) (string, error) {
+	if isKey {
+		return topic + `-key`, nil
+	}
+
+	return topic + `-value`, nil
+}
+
+// RecordNameStrategy uses the fully qualified record name as the subject
+func RecordNameStrategy(_ string, _ bool, recordName string) (string, error) {
+	if recordName == `` {
+		return ``, errors.New(`RecordNameStrategy requires values implementing RecordNamer`)
+	}
+
+	return recordName, nil
+}
+
+// TopicRecordNameStrategy uses <topic>-<fully qualified record name> as the subject
+func TopicRecordNameStrategy(topic string, _ bool, recordName string) (string, error) {
+	if recordName == `` {
+		return ``, errors.New(`TopicRecordNameStrategy requires values implementing RecordNamer`)
+	}
+
+	return topic + `-` + recordName, nil
+}
+
+// RecordNamer is implemented by values serialized using RecordNameStrategy or TopicRecordNameStrategy
+type RecordNamer interface {
+	RecordName() string // Fully qualified record name (ex: com.example.Event)
+}
+
+// SchemaProvider is implemented by values which carry their schema. Serializers register or look up the schema
+// unless use.latest.version is set
+type SchemaProvider interface {
+	Schema() string
+	SchemaType() registry.SchemaType
+}
+
+// SerdeConfig holds the serializer settings of the Confluent properties
+type SerdeConfig struct {
+	AutoRegisterSchemas       bool // auto.register.schemas (default true)
+	UseLatestVersion          bool // use.latest.version (default false), ignored when AutoRegisterSchemas is set
+	LatestCompatibilityStrict bool // latest.compatibility.strict (default true), applies to SchemaProvider values
+	KeySubjectNameStrategy    SubjectNameStrategy
+	ValueSubjectNameStrategy  SubjectNameStrategy
+}
+
+// SerdeConfigFromProperties reads the serializer settings from Confluent properties. Subject name strategies can be
+// given as Java class names (io.confluent.kafka.serializers.subject.TopicNameStrategy) or simple names. As in
+// Confluent's serializers, auto.register.schemas takes precedence over use.latest.version
+func SerdeConfigFromProperties(props map[string]string) (SerdeConfig, error) {
+	config := SerdeConfig{}
+
+	var err error
+	if config.AutoRegisterSchemas, err = boolProperty(props, PropAutoRegisterSchemas, true); err != nil {
+		return config, err
+	}
+	if config.UseLatestVersion, err = boolProperty(props, PropUseLatestVersion, false); err != nil {
+		return config, err
+	}
+	if config.LatestCompatibilityStrict, err = boolProperty(props, PropLatestCompatibilityStrict, true); err != nil {
+		return config, err
+	}
+
+	if config.KeySubjectNameStrategy, err = subjectNameStrategy(props[PropKeySubjectNameStrategy]); err != nil {
+		return config, err
+	}
+	if config.ValueSubjectNameStrategy, err = subjectNameStrategy(props[PropValueSubjectNameStrategy]); err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+func subjectNameStrategy(className string) (SubjectNameStrategy, error) {
+	name := className[strings.LastIndex(className, `.`)+1:]
+	switch name {
+	case ``, `TopicNameStrategy`:
+		return TopicNameStrategy, nil
+	case `RecordNameStrategy`:
+		return RecordNameStrategy, nil
+	case `TopicRecordNameStrategy`:
+		return TopicRecordNameStrategy, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf(`unsupported subject name strategy [%s]`, className))
+}
+
+// Serializer encodes the keys or values of topics with the subjects chosen by the subject name strategy
+type Serializer struct {
+	registry   *Registry
+	config     SerdeConfig
+	isKey      bool
+	ids        map[string]int // subject and schema -> schema ID
+	latest     map[string]*LatestEncoder
+	pinned     map[string]*Subject // Latest versions of subjects which are not registered
+	compatible map[string]error    // schema ID and value schema -> latest.compatibility.strict result
+	mu         sync.Mutex
+}
+
+// NewSerializer returns a key or value Serializer configured with Confluent serializer properties
+func NewSerializer(reg *Registry, props map[string]string, isKey bool) (*Serializer, error) {
+	config, err := SerdeConfigFromProperties(props)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.UseLatestVersion && config.AutoRegisterSchemas {
+		reg.logger.Warn(fmt.Sprintf(`%s is ignored since %s is set, set it to false to use the latest version`,
+			PropUseLatestVersion, PropAutoRegisterSchemas))
+	}
+
+	return &Serializer{
+		registry:   reg,
+		config:     config,
+		isKey:      isKey,
+		ids:        map[string]int{},
+		latest:     map[string]*LatestEncoder{},
+		pinned:     map[string]*Subject{},
+		compatible: map[string]error{},
+	}, nil
+}
+
+// Serialize encodes the value for the topic. Values implementing SchemaProvider are encoded with their schema,
+// which is registered when auto.register.schemas is set. Otherwise with use.latest.version the latest version of
+// the subject is used. It follows the versions found by the background sync for registered subjects and is looked
+// up once for others. With latest.compatibility.strict serialization fails when the latest version cannot read
+// values written with the schema of a SchemaProvider value
+func (s *Serializer) Serialize(topic string, v interface{}) ([]byte, error) {
+	strategy := s.config.ValueSubjectNameStrategy
+	if s.isKey {
+		strategy = s.config.KeySubjectNameStrategy
+	}
+
+	var recordName string
+	if namer, ok := v.(RecordNamer); ok {
+		recordName = namer.RecordName()
+	}
+
+	subject, err := strategy(topic, s.isKey, recordName)
+	if err != nil {
+		return nil, err
+	}
+	subject = s.registry.qualify(subject)
+
+	provider, ok := v.(SchemaProvider)
+	if s.config.UseLatestVersion && !s.config.AutoRegisterSchemas {
+		latest, err := s.latestSubject(subject)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.config.LatestCompatibilityStrict && ok {
+			if err := s.checkLatest(latest, provider); err != nil {
+				return nil, err
+			}
+		}
+
+		return NewRegistryEncoder(s.registry, latest).Encode(v)
+	}
+
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(`value of type %T does not implement SchemaProvider, set %s to use the latest version of %s`,
+			v, PropUseLatestVersion, subject))
+	}
+
+	id, err := s.schemaID(subject, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder, err := s.registry.WithSchemaID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.Encode(v)
+}
+
+func (s *Serializer) schemaID(subject string, provider SchemaProvider) (int, error) {
+	key := subject + "\x00" + provider.Schema()
+
+	s.mu.Lock()
+	id, ok := s.ids[key]
+	s.mu.Unlock()
+
+	if ok {
+		return id, nil
+	}
+
+	var schema *registry.Schema
+	var err error
+	if s.config.AutoRegisterSchemas {
+		schema, err = s.registry.client.CreateSchema(subject, provider.Schema(), provider.SchemaType())
+	} else {
+		schema, err = s.registry.client.LookupSchema(subject, provider.Schema(), provider.SchemaType())
+	}
+
+	if err != nil {
+		return 0, errors.WithPrevious(err, fmt.Sprintf(`resolving schema of subject %s failed`, subject))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids[key] = schema.ID()
+
+	return schema.ID(), nil
+}
+
+// latestSubject returns the latest version of the subject. Registered subjects follow the versions found by the
+// background sync, the latest version of other subjects is looked up once
+func (s *Serializer) latestSubject(subject string) (*Subject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if encoder, ok := s.latest[subject]; ok {
+		return encoder.resolve(), nil
+	}
+
+	if s.registry.subjectRegistered(subject) {
+		encoder, err := s.registry.LatestEncoder(subject, FollowAll)
+		if err != nil {
+			return nil, err
+		}
+		s.latest[subject] = encoder
+
+		return encoder.resolve(), nil
+	}
+
+	if latest, ok := s.pinned[subject]; ok {
+		return latest, nil
+	}
+
+	schema, err := s.registry.client.GetLatestSchema(subject)
+	if err != nil {
+		return nil, errors.WithPrevious(err, fmt.Sprintf(`fetching latest schema of subject %s failed`, subject))
+	}
+
+	latest, err := s.registry.subjectBySchemaID(schema.ID())
+	if err != nil {
+		return nil, err
+	}
+	s.pinned[subject] = latest
+
+	return latest, nil
+}
+
+// checkLatest fails when the latest version cannot read messages written with the schema of the value, as
+// latest.compatibility.strict does
+func (s *Serializer) checkLatest(latest *Subject, provider SchemaProvider) error {
+	key := fmt.Sprintf("%d\x00%s", latest.Id, provider.Schema())
+
+	s.mu.Lock()
+	err, ok := s.compatible[key]
+	s.mu.Unlock()
+
+	if ok {
+		return err
+	}
+
+	schemaType := provider.SchemaType()
+	writer := &Subject{
+		Subject:    latest.Subject,
+		Schema:     provider.Schema(),
+		SchemaType: schemaType,
+		marsheller: s.registry.getMarshaller(s.registry.client, &schemaType, provider.Schema(), nil),
+	}
+
+	if err = writer.marsheller.Init(); err == nil {
+		err = s.registry.checkCompatible(latest, writer)
+	}
+
+	if err != nil {
+		err = errors.WithPrevious(err, fmt.Sprintf(`latest version %s is not compatible with the schema of the value`,
+			latest))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.compatible[key] = err
+
+	return err
+}
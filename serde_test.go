@@ -0,0 +1,215 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	registry "github.com/riferrei/srclient"
+)
+
+type namedSample struct {
+	SampleV1
+}
+
+func (namedSample) RecordName() string {
+	return `com.mycorp.mynamespace.SampleRecord`
+}
+
+func (namedSample) Schema() string {
+	return testSchemas[`avro_v1`]
+}
+
+func (namedSample) SchemaType() registry.SchemaType {
+	return registry.Avro
+}
+
+// incompatibleSample carries a schema the sample schema cannot read
+type incompatibleSample struct {
+	SampleV1
+}
+
+func (incompatibleSample) Schema() string {
+	return incompatibleSchema
+}
+
+func (incompatibleSample) SchemaType() registry.SchemaType {
+	return registry.Avro
+}
+
+func TestNewRegistryFromProperties(t *testing.T) {
+	tests := map[string]map[string]string{
+		`USER_INFO`: {
+			PropBasicAuthUserInfo: `user:secret`,
+		},
+		`URL`: {
+			PropBasicAuthCredentialsSource: `URL`,
+		},
+	}
+
+	for name, props := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if username, password, ok := r.BasicAuth(); !ok || username != `user` || password != `secret` {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				_, _ = w.Write([]byte(`["test_subject"]`))
+			}))
+			t.Cleanup(srv.Close)
+
+			props[PropSchemaRegistryURL] = srv.URL
+			if name == `URL` {
+				props[PropSchemaRegistryURL] = `http://user:secret@` + srv.Listener.Addr().String()
+			}
+
+			reg, err := NewRegistryFromProperties(props)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := reg.client.GetSubjects(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	if _, err := NewRegistryFromProperties(map[string]string{}); err == nil {
+		t.Error(`need an error without a registry url`)
+	}
+}
+
+func TestSerdeConfigFromProperties(t *testing.T) {
+	config, err := SerdeConfigFromProperties(map[string]string{
+		PropValueSubjectNameStrategy: `io.confluent.kafka.serializers.subject.TopicRecordNameStrategy`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !config.AutoRegisterSchemas || config.UseLatestVersion || !config.LatestCompatibilityStrict {
+		t.Errorf(`unexpected defaults %+v`, config)
+	}
+
+	subject, err := config.ValueSubjectNameStrategy(`events`, false, `com.example.Event`)
+	if err != nil || subject != `events-com.example.Event` {
+		t.Errorf(`unexpected subject %s, %v`, subject, err)
+	}
+
+	config, err = SerdeConfigFromProperties(map[string]string{PropUseLatestVersion: `true`})
+	if err != nil {
+		t.Fatalf(`need use.latest.version to be accepted with auto.register.schemas, have %s`, err)
+	}
+
+	if !config.AutoRegisterSchemas {
+		t.Errorf(`need auto.register.schemas to keep its default, have %+v`, config)
+	}
+
+	for _, props := range []map[string]string{
+		{PropKeySubjectNameStrategy: `UnknownStrategy`},
+		{PropAutoRegisterSchemas: `maybe`},
+	} {
+		if _, err := SerdeConfigFromProperties(props); err == nil {
+			t.Errorf(`need an error for %v`, props)
+		}
+	}
+}
+
+func TestSerializer_Serialize(t *testing.T) {
+	reg := setupMockRegistry(1)
+	if _, err := reg.client.SetSchema(100, `events-value`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := NewSerializer(reg.Registry, map[string]string{
+		PropAutoRegisterSchemas: `false`,
+		PropUseLatestVersion:    `true`,
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byt, err := latest.Serialize(`events`, SampleV1{Field3: `latest`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := binary.BigEndian.Uint32(byt[1:prefixLen]); id != 100 {
+		t.Errorf(`need schema ID 100, have %d`, id)
+	}
+
+	autoRegister, err := NewSerializer(reg.Registry, map[string]string{
+		PropKeySubjectNameStrategy: `RecordNameStrategy`,
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := autoRegister.Serialize(`events`, SampleV1{}); err == nil {
+		t.Error(`need an error for values without a schema`)
+	}
+
+	if _, err := autoRegister.Serialize(`events`, namedSample{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.client.GetLatestSchema(`com.mycorp.mynamespace.SampleRecord`); err != nil {
+		t.Errorf(`need the schema to be registered under the record name, have %s`, err)
+	}
+
+	// auto.register.schemas takes precedence over use.latest.version
+	both, err := NewSerializer(reg.Registry, map[string]string{PropUseLatestVersion: `true`}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := both.Serialize(`other`, namedSample{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.client.GetLatestSchema(`other-value`); err != nil {
+		t.Errorf(`need the schema to be registered, have %s`, err)
+	}
+}
+
+func TestSerializer_LatestCompatibilityStrict(t *testing.T) {
+	reg := setupMockRegistry(1)
+	if _, err := reg.client.SetSchema(100, `events-value`, testSchemas[`avro_v1`], registry.Avro, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	strict, err := NewSerializer(reg.Registry, map[string]string{
+		PropAutoRegisterSchemas: `false`,
+		PropUseLatestVersion:    `true`,
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := strict.Serialize(`events`, namedSample{}); err != nil {
+		t.Errorf(`need a value of the latest schema to be serialized, have %s`, err)
+	}
+
+	if _, err := strict.Serialize(`events`, incompatibleSample{}); err == nil {
+		t.Error(`need an error for a value the latest version cannot read`)
+	}
+
+	lenient, err := NewSerializer(reg.Registry, map[string]string{
+		PropAutoRegisterSchemas:       `false`,
+		PropUseLatestVersion:          `true`,
+		PropLatestCompatibilityStrict: `false`,
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lenient.Serialize(`events`, incompatibleSample{}); err != nil {
+		t.Errorf(`need the compatibility check to be skipped, have %s`, err)
+	}
+
+	if reg.subjectRegistered(`events-value`) {
+		t.Error(`need serializing not to register the subject for decoding`)
+	}
+}
@@ -0,0 +1,100 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/tryfix/errors"
+)
+
+// TypedEncoder encodes and decodes messages of a single Go type T, without the need of an UnmarshalerFunc or
+// type assertions
+//
+//	encoder, err := schemaregistry.RegisterType[SampleRecord](registry, `test-subject-avro`, schemaregistry.VersionAll)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	payload, err := encoder.Encode(SampleRecord{Field1: 1})
+//	record, err := encoder.Decode(payload) // record is a SampleRecord
+type TypedEncoder[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+type typedEncoder[T any] struct {
+	registry *Registry
+	subject  string
+	version  Version
+}
+
+// RegisterType registers the subject and version in the Registry with an UnmarshalerFunc decoding into T and
+// returns a TypedEncoder for it. T can be an avro struct or a proto message pointer (ex: *pb.SampleRecord)
+//
+// Encode uses the registered version, or the latest registered version when registered with VersionAll
+func RegisterType[T any](reg *Registry, subject string, version Version, options ...RegisterOption) (TypedEncoder[T], error) {
+	if err := reg.Register(subject, version, NewUnmarshalerFunc[T](), options...); err != nil {
+		return nil, err
+	}
+
+	return &typedEncoder[T]{
+		registry: reg,
+		subject:  subject,
+		version:  version,
+	}, nil
+}
+
+// NewUnmarshalerFunc returns an UnmarshalerFunc which decodes messages into a new T. When T is a pointer type the
+// value it points to is allocated before decoding
+func NewUnmarshalerFunc[T any]() UnmarshalerFunc {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	return func(unmarshaler Unmarshaler) (interface{}, error) {
+		var v T
+		if typ.Kind() == reflect.Ptr {
+			v = reflect.New(typ.Elem()).Interface().(T)
+			if err := unmarshaler.Unmarshal(v); err != nil {
+				return nil, err
+			}
+
+			return v, nil
+		}
+
+		if err := unmarshaler.Unmarshal(&v); err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	}
+}
+
+func (e *typedEncoder[T]) encoder() Encoder {
+	if e.version == VersionAll {
+		return e.registry.WithLatestSchema(e.subject)
+	}
+
+	return e.registry.WithSchema(e.subject, e.version)
+}
+
+// Encode encodes v using the registered schema version
+func (e *typedEncoder[T]) Encode(v T) ([]byte, error) {
+	return e.encoder().Encode(v)
+}
+
+// Decode decodes the message and returns it as T. An error is returned when the schema of the message was
+// registered with a different type
+func (e *typedEncoder[T]) Decode(data []byte) (T, error) {
+	var t T
+	// Migrations would return the type of a newer version
+	v, err := e.registry.RawEncoder().Decode(data)
+	if err != nil {
+		return t, err
+	}
+
+	t, ok := v.(T)
+	if !ok {
+		return t, errors.New(fmt.Sprintf(`decoded value of type %T cannot be used as %T`, v, t))
+	}
+
+	return t, nil
+}
@@ -0,0 +1,106 @@
+package schemaregistry
+
+import (
+	"reflect"
+	"testing"
+
+	com_mycorp_mynamespace "github.com/tryfix/schemaregistry/v2/protobuf"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRegisterType(t *testing.T) {
+	reg := setupSampleRegistry(t)
+
+	encoder, err := RegisterType[SampleV1](reg.Registry, `test_subject`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := SampleV1{
+		Field1: 100,
+		Field2: 10.11,
+		Field3: "text",
+	}
+	byt, err := encoder.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vOut, err := encoder.Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(v, vOut) {
+		t.Errorf(`need %v, have %v`, v, vOut)
+	}
+}
+
+func TestRegisterType_Pointer(t *testing.T) {
+	reg := setupSampleRegistry(t)
+
+	encoder, err := RegisterType[*SampleV2](reg.Registry, `test_subject`, VersionAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := &SampleV2{
+		Field1: 100,
+		Field2: 10.11,
+		Field3: "text",
+		Field4: "text 2",
+	}
+	byt, err := encoder.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vOut, err := encoder.Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(v, vOut) {
+		t.Errorf(`need %v, have %v`, v, vOut)
+	}
+}
+
+func TestRegisterType_TypeMismatch(t *testing.T) {
+	reg := setupSampleRegistry(t)
+
+	if _, err := RegisterType[SampleV1](reg.Registry, `test_subject`, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	byt, err := reg.WithSchema(`test_subject`, 1).Encode(SampleV1{Field1: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := &typedEncoder[SampleV2]{registry: reg.Registry, subject: `test_subject`, version: 1}
+	if _, err := other.Decode(byt); err == nil {
+		t.Fatal(`expected type mismatch error`)
+	}
+}
+
+func TestNewUnmarshalerFunc_Proto(t *testing.T) {
+	marshaller := NewProtoMarshaller()
+	v := &com_mycorp_mynamespace.SampleRecord{
+		Field1: 100,
+		Field2: 10.11,
+		Field3: "text",
+	}
+	byt, err := marshaller.Marshall(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vOut, err := NewUnmarshalerFunc[*com_mycorp_mynamespace.SampleRecord]()(marshaller.NewUnmarshaler(byt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(v, vOut.(*com_mycorp_mynamespace.SampleRecord)) {
+		t.Errorf(`need %v, have %v`, v, vOut)
+	}
+}
@@ -0,0 +1,168 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tryfix/errors"
+)
+
+// VersionRange is an inclusive range of subject versions, a zero Max leaves the range open ended
+type VersionRange struct {
+	Min Version
+	Max Version
+}
+
+// ParseVersionRange parses a version range. Supported formats are an exact version (3), bounds (>=3, >2, <=2, <3),
+// an inclusive range (1..2) and * for all versions
+func ParseVersionRange(s string) (VersionRange, error) {
+	s = strings.TrimSpace(s)
+	invalid := func(err error) (VersionRange, error) {
+		return VersionRange{}, errors.WithPrevious(err, fmt.Sprintf(`invalid version range [%s]`, s))
+	}
+
+	parse := func(v string) (Version, error) {
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err == nil && i < 1 {
+			err = errors.New(`versions start from 1`)
+		}
+
+		return Version(i), err
+	}
+
+	var rng VersionRange
+	var err error
+	switch {
+	case s == `*`:
+		return VersionRange{Min: 1}, nil
+	case strings.Contains(s, `..`):
+		bounds := strings.SplitN(s, `..`, 2)
+		if rng.Min, err = parse(bounds[0]); err != nil {
+			return invalid(err)
+		}
+		if rng.Max, err = parse(bounds[1]); err != nil {
+			return invalid(err)
+		}
+	case strings.HasPrefix(s, `>=`):
+		rng.Min, err = parse(s[2:])
+	case strings.HasPrefix(s, `>`):
+		rng.Min, err = parse(s[1:])
+		rng.Min++
+	case strings.HasPrefix(s, `<=`):
+		rng.Min = 1
+		rng.Max, err = parse(s[2:])
+	case strings.HasPrefix(s, `<`):
+		rng.Min = 1
+		rng.Max, err = parse(s[1:])
+		rng.Max--
+		if err == nil && rng.Max < 1 {
+			err = errors.New(`range is empty`)
+		}
+	default:
+		rng.Min, err = parse(s)
+		rng.Max = rng.Min
+	}
+
+	if err != nil {
+		return invalid(err)
+	}
+
+	if rng.Max != 0 && rng.Max < rng.Min {
+		return invalid(errors.New(`range is empty`))
+	}
+
+	return rng, nil
+}
+
+// Contains reports whether the version is within the range
+func (r VersionRange) Contains(v Version) bool {
+	return v >= r.Min && (r.Max == 0 || v <= r.Max)
+}
+
+func (r VersionRange) String() string {
+	if r.Max == 0 {
+		return fmt.Sprintf(`>=%d`, r.Min)
+	}
+
+	return fmt.Sprintf(`%d..%d`, r.Min, r.Max)
+}
+
+type rangeUnmarshaler struct {
+	versions        VersionRange
+	unmarshalerFunc UnmarshalerFunc
+}
+
+// subjectUnmarshalers holds the UnmarshalerFuncs of a subject
+type subjectUnmarshalers struct {
+	versions map[Version]UnmarshalerFunc // Registered using Register
+	ranges   []rangeUnmarshaler
+	fallback UnmarshalerFunc
+}
+
+// resolve returns the UnmarshalerFunc of the version. Versions registered using Register take precedence, followed
+// by the most recently registered matching range, the default and finally the func of the highest registered version
+func (u *subjectUnmarshalers) resolve(version Version) (UnmarshalerFunc, bool) {
+	if fn, ok := u.versions[version]; ok {
+		return fn, true
+	}
+
+	for i := len(u.ranges) - 1; i >= 0; i-- {
+		if u.ranges[i].versions.Contains(version) {
+			return u.ranges[i].unmarshalerFunc, true
+		}
+	}
+
+	if u.fallback != nil {
+		return u.fallback, true
+	}
+
+	var highest Version
+	for v := range u.versions {
+		if v > highest {
+			highest = v
+		}
+	}
+
+	fn, ok := u.versions[highest]
+
+	return fn, ok
+}
+
+// RegisterUnmarshaler sets the UnmarshalerFunc used for versions of the subject within the range (see
+// ParseVersionRange) which are added later by the background sync or by schema ID lookups. Versions registered
+// using Register keep their own UnmarshalerFunc
+func (r *Registry) RegisterUnmarshaler(subject, versions string, unmarshalerFunc UnmarshalerFunc) error {
+	rng, err := ParseVersionRange(versions)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := r.subjectUnmarshalers(r.qualify(subject))
+	u.ranges = append(u.ranges, rangeUnmarshaler{versions: rng, unmarshalerFunc: unmarshalerFunc})
+
+	return nil
+}
+
+// SetDefaultUnmarshaler sets the UnmarshalerFunc used for versions of the subject which neither Register nor
+// RegisterUnmarshaler cover. Without a default the func of the highest registered version is used
+func (r *Registry) SetDefaultUnmarshaler(subject string, unmarshalerFunc UnmarshalerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subjectUnmarshalers(r.qualify(subject)).fallback = unmarshalerFunc
+}
+
+// subjectUnmarshalers must be called while holding the lock
+func (r *Registry) subjectUnmarshalers(subject string) *subjectUnmarshalers {
+	u, ok := r.unmarshalers[subject]
+	if !ok {
+		u = &subjectUnmarshalers{versions: map[Version]UnmarshalerFunc{}}
+		r.unmarshalers[subject] = u
+	}
+
+	return u
+}
@@ -0,0 +1,102 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	"github.com/tryfix/log"
+)
+
+func TestParseVersionRange(t *testing.T) {
+	tests := map[string]VersionRange{
+		`3`:    {Min: 3, Max: 3},
+		`>=3`:  {Min: 3},
+		`>2`:   {Min: 3},
+		`<=2`:  {Min: 1, Max: 2},
+		`<3`:   {Min: 1, Max: 2},
+		`1..2`: {Min: 1, Max: 2},
+		`*`:    {Min: 1},
+	}
+
+	for s, want := range tests {
+		rng, err := ParseVersionRange(s)
+		if err != nil {
+			t.Errorf(`parsing %s failed due to %s`, s, err)
+			continue
+		}
+
+		if rng != want {
+			t.Errorf(`need %s for %s, have %s`, want, s, rng)
+		}
+	}
+
+	for _, s := range []string{``, `0`, `<1`, `3..1`, `>=x`} {
+		if _, err := ParseVersionRange(s); err == nil {
+			t.Errorf(`need an error for %s`, s)
+		}
+	}
+}
+
+func TestRegistry_RegisterUnmarshaler(t *testing.T) {
+	reg := setupSampleRegistry(t)
+
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.RegisterUnmarshaler(`test_subject`, `>=2`, NewUnmarshalerFunc[SampleV2]()); err != nil {
+		t.Fatal(err)
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	for version, want := range map[Version]interface{}{1: SampleV1{}, 2: SampleV2{}} {
+		byt, err := reg.WithSchema(`test_subject`, version).Encode(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := reg.RawEncoder().Decode(byt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := v.(SampleV1); ok != (version == 1) {
+			t.Errorf(`unexpected type %T decoded for version %d`, v, version)
+		}
+	}
+}
+
+func TestRegistry_SetDefaultUnmarshaler(t *testing.T) {
+	reg := setupSampleRegistry(t)
+
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	reg.mu.RLock()
+	fn, _ := reg.unmarshalers[`test_subject`].resolve(2)
+	reg.mu.RUnlock()
+	if fn == nil {
+		t.Fatal(`need the func of the highest registered version without a default`)
+	}
+
+	reg.SetDefaultUnmarshaler(`test_subject`, NewUnmarshalerFunc[SampleV2]())
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	byt, err := reg.WithSchema(`test_subject`, 2).Encode(SampleV2{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := reg.GenericEncoder().Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := v.(SampleV2); !ok {
+		t.Errorf(`need SampleV2, have %T`, v)
+	}
+}
@@ -0,0 +1,104 @@
+package schemaregistry
+
+import (
+	"fmt"
+
+	"github.com/tryfix/errors"
+)
+
+// Unregister removes the subject with all its versions, unmarshalers and migrations from the Registry. The
+// background sync stops checking the subject, including discovery by RegisterPattern, until it is registered again
+func (r *Registry) Unregister(subject string) error {
+	subject = r.qualify(subject)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subjects[subject]; !ok {
+		return errors.New(fmt.Sprintf(`subject [%s] not registered`, subject))
+	}
+
+	r.releaseSubject(subject)
+
+	r.logger.Info(fmt.Sprintf(`Subject %s unregistered`, subject))
+
+	return nil
+}
+
+// UnregisterVersion removes a version of the subject from the Registry. The background sync does not add the version
+// back until it is registered again. Removing the last version unregisters the subject
+func (r *Registry) UnregisterVersion(subject string, version Version) error {
+	subject = r.qualify(subject)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.subjects[subject][version]
+	if !ok {
+		return errors.New(fmt.Sprintf(`subject [%s][%s] not registered`, subject, version))
+	}
+
+	if len(r.subjects[subject]) == 1 {
+		r.releaseSubject(subject)
+		r.logger.Info(fmt.Sprintf(`Subject %s unregistered`, subject))
+
+		return nil
+	}
+
+	delete(r.subjects[subject], version)
+	delete(r.idMap, s.key())
+	if u, ok := r.unmarshalers[subject]; ok {
+		delete(u.versions, version)
+	}
+
+	if _, ok := r.released[subject]; !ok {
+		r.released[subject] = map[Version]bool{}
+	}
+	r.released[subject][version] = true
+
+	r.logger.Info(fmt.Sprintf(`Subject %s unregistered`, s))
+
+	return nil
+}
+
+// removeSubject must be called while holding the lock
+func (r *Registry) removeSubject(subject string) {
+	for _, s := range r.subjects[subject] {
+		delete(r.idMap, s.key())
+	}
+
+	delete(r.subjects, subject)
+	delete(r.unmarshalers, subject)
+	delete(r.migrations, subject)
+	delete(r.released, subject)
+	delete(r.deletedChecks, subject)
+}
+
+// releaseSubject removes the subject and keeps it from being added back by the background sync, must be called while
+// holding the lock. Only subjects matching a registered pattern are marked, the sync does not add others back
+func (r *Registry) releaseSubject(subject string) {
+	r.removeSubject(subject)
+	if _, ok := r.patternFor(subject); ok {
+		r.released[subject] = map[Version]bool{VersionAll: true}
+	}
+}
+
+// isReleased reports whether the version, or the whole subject when version is VersionAll, was unregistered
+func (r *Registry) isReleased(subject string, version Version) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	released := r.released[subject]
+
+	return released[VersionAll] || released[version]
+}
+
+// clearReleased must be called while holding the lock
+func (r *Registry) clearReleased(subject string, version Version) {
+	if r.released[subject][VersionAll] {
+		delete(r.released, subject)
+		return
+	}
+
+	delete(r.released[subject], version)
+}
@@ -0,0 +1,88 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	"github.com/tryfix/log"
+)
+
+func TestRegistry_Unregister(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if err := reg.Register(`test_subject`, VersionAll, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.RegisterPattern(SubjectPrefix(`test_`), NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Unregister(`test_subject`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Unregister(`test_subject`); err == nil {
+		t.Error(`need an error for an unregistered subject`)
+	}
+
+	health := reg.Health()
+	if health.CachedSubjects != 0 || health.CachedIDs != 0 || len(reg.unmarshalers) != 0 {
+		t.Errorf(`need every index to be cleaned, have %+v`, health)
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	if reg.Versions(`test_subject`) != nil {
+		t.Error(`need the background sync not to register the subject again`)
+	}
+
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	sync.checkRegistryAndAdd()
+	if versions := reg.Versions(`test_subject`); len(versions) != 2 {
+		t.Errorf(`need the sync to resume after registering again, have %d versions`, len(versions))
+	}
+}
+
+func TestRegistry_UnregisterVersion(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if err := reg.Register(`test_subject`, VersionAll, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.UnregisterVersion(`test_subject`, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.UnregisterVersion(`test_subject`, 2); err == nil {
+		t.Error(`need an error for an unregistered version`)
+	}
+
+	if _, ok := reg.SubjectByID(101); ok {
+		t.Error(`need schema ID 101 to be removed`)
+	}
+
+	sync := &backgroundSync{registry: reg.Registry, logger: log.NewNoopLogger()}
+	sync.checkRegistryAndAdd()
+
+	if versions := reg.Versions(`test_subject`); len(versions) != 1 || versions[0].Version != 1 {
+		t.Errorf(`need only version 1 to be registered, have %+v`, versions)
+	}
+
+	if _, err := reg.WithSchemaID(101); err == nil {
+		t.Error(`need schema ID lookups not to add the unregistered version back`)
+	}
+
+	if err := reg.UnregisterVersion(`test_subject`, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if reg.subjectRegistered(`test_subject`) {
+		t.Error(`need removing the last version to unregister the subject`)
+	}
+
+	if len(reg.released) != 0 {
+		t.Errorf(`need no markers for subjects without a matching pattern, have %v`, reg.released)
+	}
+}
@@ -0,0 +1,77 @@
+package schemaregistry
+
+import (
+	"fmt"
+
+	"github.com/tryfix/errors"
+)
+
+// MigrationFunc converts a decoded value of one subject version into the value of a newer version
+type MigrationFunc func(v interface{}) (interface{}, error)
+
+type migration struct {
+	to Version
+	fn MigrationFunc
+}
+
+// RegisterMigration registers a MigrationFunc which upcasts decoded values of the subject's from version into the
+// to version. Decoders returned by GenericEncoder and ContextEncoder chain the registered migrations (ex: v1→v2,
+// v2→v3) so consumers always receive the newest type. Encoders of a subject version, TypedEncoders and RawEncoder
+// decode values without migrations
+func (r *Registry) RegisterMigration(subject string, from, to Version, fn MigrationFunc) error {
+	subject = r.qualify(subject)
+	if from < 1 || to <= from {
+		return errors.New(fmt.Sprintf(`invalid migration %s:%s -> %s, migrations must move to a newer version`,
+			subject, from, to))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.migrations[subject]; !ok {
+		r.migrations[subject] = map[Version]migration{}
+	}
+
+	if _, ok := r.migrations[subject][from]; ok {
+		r.logger.Warn(fmt.Sprintf(`Migration for [%s][%s] already registred, overriding`, subject, from))
+	}
+
+	r.migrations[subject][from] = migration{to: to, fn: fn}
+
+	return nil
+}
+
+// RawEncoder returns a placeholder encoder for decoders which, unlike GenericEncoder, returns the type registered
+// for the writer's version without applying migrations
+func (r *Registry) RawEncoder() Encoder {
+	return &GenericEncoder{&RegistryEncoder{registry: r, schemaContext: r.options.schemaContext}}
+}
+
+func (r *Registry) getMigration(subject string, from Version) (migration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.migrations[subject][from]
+
+	return m, ok
+}
+
+// upcast applies the migration chain starting at the subject's version
+func (r *Registry) upcast(subject *Subject, v interface{}) (interface{}, error) {
+	version := subject.Version
+	for {
+		m, ok := r.getMigration(subject.Subject, version)
+		if !ok {
+			return v, nil
+		}
+
+		migrated, err := m.fn(v)
+		if err != nil {
+			return nil, errors.WithPrevious(err, fmt.Sprintf(`migrating %s:%s to version %s failed`,
+				subject.Subject, version, m.to))
+		}
+
+		v = migrated
+		version = m.to
+	}
+}
@@ -0,0 +1,152 @@
+package schemaregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+// migrateSampleV1 upgrades SampleV1 values to SampleV2
+func migrateSampleV1(v interface{}) (interface{}, error) {
+	v1 := v.(SampleV1)
+	return SampleV2{
+		Field1: v1.Field1,
+		Field2: v1.Field2,
+		Field3: v1.Field3,
+		Field4: `migrated`,
+	}, nil
+}
+
+func TestRegistry_RegisterMigration(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, 2, NewUnmarshalerFunc[SampleV2]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.RegisterMigration(`test_subject`, 1, 2, migrateSampleV1); err != nil {
+		t.Fatal(err)
+	}
+
+	byt, err := reg.WithSchema(`test_subject`, 1).Encode(SampleV1{Field1: 100, Field2: 10.11, Field3: "text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vOut, err := reg.GenericEncoder().Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	need := SampleV2{Field1: 100, Field2: 10.11, Field3: "text", Field4: `migrated`}
+	if !reflect.DeepEqual(need, vOut) {
+		t.Errorf(`need %v, have %v`, need, vOut)
+	}
+
+	// values already on the newest version are returned as is
+	v2 := SampleV2{Field1: 1, Field4: `v2`}
+	byt, err = reg.WithSchema(`test_subject`, 2).Encode(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vOut, err = reg.GenericEncoder().Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(v2, vOut) {
+		t.Errorf(`need %v, have %v`, v2, vOut)
+	}
+}
+
+func TestRegistry_RawEncoder(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, 2, NewUnmarshalerFunc[SampleV2]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.RegisterMigration(`test_subject`, 1, 2, migrateSampleV1); err != nil {
+		t.Fatal(err)
+	}
+
+	v := SampleV1{Field1: 100, Field2: 10.11, Field3: "text"}
+	byt, err := reg.WithSchema(`test_subject`, 1).Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vOut, err := reg.RawEncoder().Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(v, vOut) {
+		t.Errorf(`need %v, have %v`, v, vOut)
+	}
+}
+
+func TestRegistry_RegisterMigrationInvalid(t *testing.T) {
+	reg := setupMockRegistry(1)
+	if err := reg.RegisterMigration(`test_subject`, 2, 1, func(v interface{}) (interface{}, error) {
+		return v, nil
+	}); err == nil {
+		t.Fatal(`expected error for a downgrade migration`)
+	}
+}
+
+func TestRegistry_RegisterMigrationPinnedEncoders(t *testing.T) {
+	reg := setupSampleRegistry(t)
+	if err := reg.Register(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(`test_subject`, 2, NewUnmarshalerFunc[SampleV2]()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.RegisterMigration(`test_subject`, 1, 2, migrateSampleV1); err != nil {
+		t.Fatal(err)
+	}
+	typed, err := RegisterType[SampleV1](reg.Registry, `test_subject`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byt, err := typed.Encode(SampleV1{Field1: 100, Field3: "text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v1, err := typed.Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v1.Field3 != `text` {
+		t.Errorf(`need the TypedEncoder to decode its own version, have %+v`, v1)
+	}
+
+	vOut, err := reg.WithSchema(`test_subject`, 1).Decode(byt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := vOut.(SampleV1); !ok {
+		t.Errorf(`need the version 1 encoder to decode without migrations, have %T`, vOut)
+	}
+
+	if vOut, err = reg.GenericEncoder().Decode(byt); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := vOut.(SampleV2); !ok {
+		t.Errorf(`need GenericEncoder to apply migrations, have %T`, vOut)
+	}
+}
@@ -0,0 +1,119 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SubjectRequirement describes a subject version a service needs before it can start
+type SubjectRequirement struct {
+	Subject         string
+	Version         Version
+	UnmarshalerFunc UnmarshalerFunc
+	Options         []RegisterOption
+}
+
+// Require returns a SubjectRequirement for WaitFor
+func Require(subject string, version Version, unmarshalerFunc UnmarshalerFunc, options ...RegisterOption) SubjectRequirement {
+	return SubjectRequirement{
+		Subject:         subject,
+		Version:         version,
+		UnmarshalerFunc: unmarshalerFunc,
+		Options:         options,
+	}
+}
+
+func (r SubjectRequirement) String() string {
+	return fmt.Sprintf(`%s:%s`, r.Subject, r.Version)
+}
+
+// WithWaitInterval sets the interval WaitFor retries missing subjects at. It defaults to the background sync
+// interval, which is 10s unless set using WithBackgroundSync
+func WithWaitInterval(interval time.Duration) Option {
+	return func(options *Options) {
+		options.waitInterval = interval
+	}
+}
+
+// MissingSubjectsError is returned by WaitFor when the context expires before all the required subjects are
+// available
+type MissingSubjectsError struct {
+	Subjects []string         // Requirements (subject:version) which were still missing
+	Errors   map[string]error // Last registration error of each missing requirement, if any
+	Err      error            // The context error
+}
+
+func (e *MissingSubjectsError) Error() string {
+	subjects := make([]string, len(e.Subjects))
+	for i, subject := range e.Subjects {
+		subjects[i] = subject
+		if err, ok := e.Errors[subject]; ok {
+			subjects[i] = fmt.Sprintf(`%s (%s)`, subject, err)
+		}
+	}
+
+	return fmt.Sprintf(`required subjects [%s] not available due to %s`, strings.Join(subjects, `, `), e.Err)
+}
+
+// Unwrap returns the context error followed by the registration errors
+func (e *MissingSubjectsError) Unwrap() []error {
+	errs := []error{e.Err}
+	for _, subject := range e.Subjects {
+		if err, ok := e.Errors[subject]; ok {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// WaitFor registers the required subjects, retrying at the wait interval (see WithWaitInterval) until every one of
+// them is available in the registry or the context expires. A *MissingSubjectsError listing the subjects still
+// missing and their last registration errors is returned when it gives up, permanent failures (ex: an invalid
+// schema or an authentication error) can be told apart from subjects which are not published yet through it
+func (r *Registry) WaitFor(ctx context.Context, requirements ...SubjectRequirement) error {
+	interval := r.options.waitInterval
+	if interval <= 0 {
+		interval = r.options.backgroundSync.syncInterval
+	}
+
+	missing := requirements
+	lastErrs := map[string]error{}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var pending []SubjectRequirement
+		for _, req := range missing {
+			err := r.Register(req.Subject, req.Version, req.UnmarshalerFunc, req.Options...)
+			if err == nil && r.subjectRegistered(r.qualify(req.Subject)) {
+				delete(lastErrs, req.String())
+				continue
+			}
+
+			if err != nil {
+				r.logger.Debug(fmt.Sprintf(`Waiting for subject %s due to %s`, req, err))
+				lastErrs[req.String()] = err
+			}
+			pending = append(pending, req)
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+		missing = pending
+
+		select {
+		case <-ctx.Done():
+			names := make([]string, len(missing))
+			for i, req := range missing {
+				names[i] = req.String()
+			}
+
+			return &MissingSubjectsError{Subjects: names, Errors: lastErrs, Err: ctx.Err()}
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	registry "github.com/riferrei/srclient"
+)
+
+func TestRegistry_WaitFor(t *testing.T) {
+	var published int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&published) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			`subject`: `test_subject`,
+			`version`: 1,
+			`id`:      100,
+			`schema`:  testSchemas[`avro_v1`],
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	reg, err := NewRegistry(srv.URL, WithBackgroundSync(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.AfterFunc(30*time.Millisecond, func() {
+		atomic.StoreInt32(&published, 1)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := reg.WaitFor(ctx, Require(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]())); err != nil {
+		t.Fatal(err)
+	}
+
+	if reg.Versions(`test_subject`) == nil {
+		t.Error(`need test_subject to be registered`)
+	}
+}
+
+// deniedClient refuses access to the versions of subject
+type deniedClient struct {
+	*registry.MockSchemaRegistryClient
+	subject string
+}
+
+func (c *deniedClient) GetSchemaByVersion(subject string, version int) (*registry.Schema, error) {
+	if subject == c.subject {
+		return nil, registry.Error{Code: 40301, Message: `User is denied operation on subject`}
+	}
+
+	return c.MockSchemaRegistryClient.GetSchemaByVersion(subject, version)
+}
+
+func TestRegistry_WaitForTimeout(t *testing.T) {
+	reg := setupSampleRegistry(t, WithBackgroundSync(time.Hour), WithWaitInterval(10*time.Millisecond))
+
+	reg.Registry.client.(*registryClient).endpoints[0].client = &deniedClient{
+		MockSchemaRegistryClient: reg.client,
+		subject:                  `test_subject_denied`,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := reg.WaitFor(ctx,
+		Require(`test_subject`, 1, NewUnmarshalerFunc[SampleV1]()),
+		Require(`test_subject_other`, VersionAll, NewUnmarshalerFunc[SampleV1]()),
+		Require(`test_subject_denied`, 1, NewUnmarshalerFunc[SampleV1]()),
+	)
+
+	var missing *MissingSubjectsError
+	if !stderrors.As(err, &missing) {
+		t.Fatalf(`need MissingSubjectsError, have %v`, err)
+	}
+
+	if len(missing.Subjects) != 2 || missing.Subjects[0] != `test_subject_other:All` {
+		t.Errorf(`unexpected missing subjects %v`, missing.Subjects)
+	}
+
+	if missing.Errors[`test_subject_other:All`] != nil || missing.Errors[`test_subject_denied:1`] == nil {
+		t.Errorf(`need only the registration error of the denied subject, have %v`, missing.Errors)
+	}
+
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf(`need context deadline error, have %v`, err)
+	}
+}